@@ -6,156 +6,174 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 
+	"github.com/vvvdwbvvv/rover/internal/config"
 	"github.com/vvvdwbvvv/rover/pkg/model"
 	"github.com/vvvdwbvvv/rover/pkg/storage"
 
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/compose-spec/compose-go/interpolation"
-	"github.com/compose-spec/compose-go/loader"
-	"github.com/compose-spec/compose-go/types"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
-// applyCmd 解析 docker-compose.yml
+// applyCmd 解析 compose 設定檔並啟動服務
 var applyCmd = &cobra.Command{
-	Use:   "apply",
-	Short: "Parse docker-compose.yml and run",
+	Use:   "apply [service...]",
+	Short: "Parse the compose file and run",
+	Long: `Apply every service in the compose file, or only the named services plus their depends_on (use --no-deps to skip them).
+Containers whose configuration and image are unchanged keep running; use --force-recreate to recreate them anyway.
+Services that mount secrets or configs are also recreated when the contents of those secrets or configs change.
+With --atomic, each new container must become ready before the next service starts: containers with a healthcheck
+must report healthy within 60s, others must still be running after a short observation window. A service that fails
+or does not become ready aborts the apply: the new containers are removed and the previous ones restored. apply exits with a non-zero status whenever a service fails.`,
 	Run: func(cmd *cobra.Command, args []string) {
 
-		db, err := storage.NewBoltDB("rover.db")
-		if err != nil {
-			log.Fatal(err)
-		}
+		db := openStore(cmd)
 		defer db.Close()
 
-		filePath, _ := cmd.Flags().GetString("file")
-		if filePath == "" {
-			filePath = "docker-compose.yml"
-		}
+		filePath := configFile(cmd)
+		noDeps, _ := cmd.Flags().GetBool("no-deps")
 
 		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			log.Fatalf("docker-compose.yml does not exist: %s", filePath)
+			log.Fatalf("Compose file does not exist: %s", filePath)
 		}
 
 		// 解析 Compose 文件
-		services, err := parseComposeFile(filePath)
+		project, err := config.LoadFile(filePath)
 		if err != nil {
 			log.Fatalf("Parse Compose failed: %v", err)
 		}
 
-		// 啟動容器（按照 depends_on 順序）
-		started := make(map[string]bool)
-		visiting := make(map[string]bool)
-		for _, service := range services {
-			if err := startContainerWithPodman(service, started, services, visiting); err != nil {
-				log.Printf("Container %s launch failed: %v", service.Name, err)
-			} else {
-				// 存入 BoltDB
-				container := model.ContainerState{
-					Name:      service.Name,
-					ID:        service.Name, // 假設 ID 與 Name 相同
-					Status:    "running",
-					CreatedAt: time.Now(),
-				}
-				db.SaveContainer(container)
-			}
+		// 有 rover.lock 時使用鎖定的 digest
+		project, err = pinImages(project)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// 依照 --profile / COMPOSE_PROFILES 過濾服務，明確指定的服務會自動啟用
+		profileFlags, _ := cmd.Flags().GetStringSlice("profile")
+		profiles := config.ActiveProfiles(profileFlags)
+		project, err = project.ApplyProfiles(profiles, args)
+		if err != nil {
+			log.Fatalf("Apply profiles failed: %v", err)
 		}
 
-		fmt.Println("✅ All containers started successfully")
+		selected, err := project.WithServices(args, !noDeps)
+		if err != nil {
+			log.Fatalf("Select services failed: %v", err)
+		}
+
+		forceBuild, _ := cmd.Flags().GetBool("build")
+		forceRecreate, _ := cmd.Flags().GetBool("force-recreate")
+		atomic, _ := cmd.Flags().GetBool("atomic")
+		configFile, _ := filepath.Abs(filePath)
+		result := deploy(db, deployment{
+			project:       project,
+			selected:      selected,
+			configFile:    configFile,
+			profiles:      profiles,
+			forceBuild:    forceBuild,
+			forceRecreate: forceRecreate,
+			atomic:        atomic,
+		})
+
+		if !result.printSummary() {
+			db.Close()
+			os.Exit(1)
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(applyCmd)
-	applyCmd.Flags().StringP("file", "f", "docker-compose.yml", "Compose YAML file")
+	applyCmd.Flags().StringP("file", "f", "", "Compose file (searched in the current and parent directories by default)")
+	applyCmd.Flags().Bool("no-deps", false, "Don't apply the depends_on of the named services")
+	applyCmd.Flags().Bool("build", false, "Build images before starting containers, even if they exist")
+	applyCmd.Flags().Bool("force-recreate", false, "Recreate containers even if their configuration and image are unchanged")
+	applyCmd.Flags().Bool("atomic", false, "Stop at the first failed service and restore the containers that were running before")
 }
 
-// 解析 compose.yml 並返回服務配置
-func parseComposeFile(filePath string) ([]types.ServiceConfig, error) {
-	// 讀取 YAML 檔案
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, err
+// startingContainerState 建立服務容器的記錄並轉換為 starting；沿用同名容器先前的記錄以保留狀態變更歷程
+func startingContainerState(db storage.Store, project string, revision int, hash string, service config.Service) *model.ContainerState {
+	container := &model.ContainerState{Name: service.Name}
+	if previous, err := db.GetContainer(service.Name); err == nil && previous.Status.CanTransition(model.StatusStarting) {
+		container = previous
 	}
 
-	// 轉換為 map[string]interface{}
-	var composeFile map[string]interface{}
-	if err := yaml.Unmarshal(data, &composeFile); err != nil {
-		return nil, err
-	}
+	container.ID = ""
+	container.Project = project
+	container.Service = service.Name
+	container.Replica = 1
+	container.Image = service.Image
+	container.Revision = revision
+	container.Profiles = service.Profiles
+	container.CreatedAt = time.Now()
+	container.ConfigHash = hash
+	transitionContainer(db, container, model.StatusStarting)
+	return container
+}
 
-	// 取得環境變數
-	envs := map[string]string{}
-	for _, e := range os.Environ() {
-		parts := strings.SplitN(e, "=", 2)
-		if len(parts) == 2 {
-			envs[parts[0]] = parts[1]
+// failedDependency 回傳第一個啟動失敗且為必要（required）的依賴名稱，沒有則回傳空字串
+func failedDependency(service config.Service, failed map[string]bool) string {
+	for _, dep := range service.DependencyNames() {
+		if failed[dep] && service.Requires(dep) {
+			return dep
 		}
 	}
-
-	// 解析 Compose 配置
-	cfg, err := loader.Load(types.ConfigDetails{
-		ConfigFiles: []types.ConfigFile{
-			{Filename: filePath, Config: composeFile},
-		},
-		Environment: envs,
-	}, func(options *loader.Options) {
-		options.SkipNormalization = true
-		options.Interpolate = &interpolation.Options{}
-		options.SkipValidation = false
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return cfg.Services, nil
+	return ""
 }
 
-// 用 Podman 啟動容器，確保 `depends_on` 的容器先啟動
-func startContainerWithPodman(service types.ServiceConfig, started map[string]bool, allServices []types.ServiceConfig, visiting map[string]bool) error {
-	// 先處理 `depends_on`
-	for depName := range service.DependsOn {
-		// 避免循環依賴（如果 `visiting` 中已經有這個容器）
-		if visiting[depName] {
-			return fmt.Errorf("detected circular dependency: %s depends on %s, but it is already in the startup process", service.Name, depName)
+// mergeProjectState 將本次啟動的服務合併進既有的專案記錄，
+// 讓部分 apply 不會覆蓋先前啟動的服務；透過指定服務啟用的 profiles 也一併記錄
+func mergeProjectState(db storage.Store, state model.ProjectState, project config.RoverCompose, started map[string]bool) model.ProjectState {
+	services := make(map[string]bool)
+	profiles := make(map[string]bool)
+	secrets := make(map[string]bool)
+	for _, name := range state.Secrets {
+		secrets[name] = true
+	}
+	if previous, err := db.GetProject(state.Name); err == nil {
+		for _, name := range previous.Secrets {
+			secrets[name] = true
+		}
+		for _, name := range previous.Services {
+			services[name] = true
 		}
+		for _, p := range previous.Profiles {
+			profiles[p] = true
+		}
+	}
+	for _, p := range state.Profiles {
+		profiles[p] = true
+	}
+	for name := range started {
+		services[name] = true
+		for _, p := range project.Services[name].Profiles {
+			profiles[p] = true
+		}
+	}
 
-		// 如果 `depends_on` 容器還沒啟動，則先啟動
-		if !started[depName] {
-			// 在所有服務中尋找 `depName`
-			found := false
-			for _, s := range allServices {
-				if s.Name == depName {
-					fmt.Printf("Wait for dependency %s launching...\n", depName)
-
-					// 標記當前容器，避免循環依賴s
-					visiting[service.Name] = true
-
-					// 啟動 `depends_on` 容器
-					if err := startContainerWithPodman(s, started, allServices, visiting); err != nil {
-						fmt.Printf("Cannot launch dependent container %s: %v\n", depName, err)
-						return err
-					}
-
-					// 依賴已啟動，取消標記
-					delete(visiting, service.Name)
-					found = true
-					break
-				}
-			}
+	state.Services = sortedKeys(services)
+	state.Profiles = sortedKeys(profiles)
+	state.Secrets = sortedKeys(secrets)
+	return state
+}
 
-			// 如果 `depends_on` 服務在 `docker-compose.yml` 中找不到
-			if !found {
-				return fmt.Errorf("dependent container %s is not dependent in `docker-compose.yml`", depName)
-			}
-		}
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
+	return keys
+}
 
+// 用 Podman 啟動容器；呼叫端需依照 GetServiceStartupOrder 的順序啟動，確保 `depends_on` 已先啟動。
+// mounts 為 secrets / configs 的 --secret 參數，labels 供 ps、state sync 與 state rebuild 辨識容器
+func startContainerWithPodman(project config.RoverCompose, service config.Service, mounts []string, labels map[string]string) error {
 	// 如果容器已經存在，先刪除
 	if isContainerRunning(service.Name) {
 		fmt.Printf("Container %s already exists, removing it...\n", service.Name)
@@ -164,39 +182,63 @@ func startContainerWithPodman(service types.ServiceConfig, started map[string]bo
 	}
 
 	// 構建 Podman run 命令
-	args := []string{"run", "-d", "--name", service.Name}
+	// 映像檔已在 prefetch 階段取得，避免 podman run 再逐一下載
+	args := []string{"run", "-d", "--pull", "never", "--name", service.Name}
 
 	// 設置環境變數
 	for key, value := range service.Environment {
-		if value != nil {
-			args = append(args, "-e", fmt.Sprintf("%s=%s", key, *value))
-		} else {
-			args = append(args, "-e", key)
-		}
+		args = append(args, "-e", fmt.Sprintf("%s=%s", key, value))
 	}
 
 	// 設置端口
 	for _, port := range service.Ports {
-		args = append(args, "-p", fmt.Sprintf("%s:%s", port.Published, strconv.Itoa(int(port.Target))))
+		args = append(args, "-p", port)
 	}
 
-	// 設置網路
-	if service.NetworkMode != "" {
+	// 設置網路；network_mode: service:x 對應到該服務的容器
+	if name, ok := strings.CutPrefix(service.NetworkMode, "service:"); ok {
+		args = append(args, "--network", "container:"+name)
+	} else if service.NetworkMode != "" {
 		args = append(args, "--network", service.NetworkMode)
 	}
 
-	// 設置 volumes
+	// 設置 links：其他服務以 links 指定的別名設為本容器的 network alias
+	if aliases := project.LinkAliases(service.Name); len(aliases) > 0 {
+		if service.SupportsNetworkAliases() {
+			for _, alias := range aliases {
+				args = append(args, "--network-alias", alias)
+			}
+		} else {
+			log.Printf("Warning: links to %s as %s ignored: network_mode %s does not support aliases", service.Name, strings.Join(aliases, ", "), service.NetworkMode)
+		}
+	}
+
+	// 設置 volumes；相對路徑的 bind mount 以專案目錄為基準
 	for _, volume := range service.Volumes {
-		args = append(args, "-v", fmt.Sprintf("%s:%s", volume.Source, volume.Target))
+		args = append(args, "-v", project.HostVolume(volume))
 	}
 
+	// 設置 volumes_from；container:name 直接指定容器名稱
+	for _, from := range service.VolumesFrom {
+		args = append(args, "--volumes-from", strings.TrimPrefix(from, "container:"))
+	}
+
+	// 設置 labels
+	for _, key := range sortedStringKeys(labels) {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", key, labels[key]))
+	}
+
+	// 設置 secrets / configs
+	args = append(args, mounts...)
+
 	// 設置 restart 策略
 	if service.Restart != "" {
 		args = append(args, "--restart", service.Restart)
 	}
 
-	// 設置 image
+	// 設置 image 與 command
 	args = append(args, service.Image)
+	args = append(args, service.Command...)
 
 	// 執行 Podman 命令
 	cmd := exec.Command("podman", args...)
@@ -208,7 +250,6 @@ func startContainerWithPodman(service types.ServiceConfig, started map[string]bo
 	}
 
 	fmt.Printf("Container %s started successfully\n", service.Name)
-	started[service.Name] = true
 	return nil
 }
 
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vvvdwbvvv/rover/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// buildCmd 依 build 區段建置映像檔
+var buildCmd = &cobra.Command{
+	Use:   "build [service...]",
+	Short: "Build images for services with a build section",
+	Long:  `Build the images of every service (or only the named services) that has a build section, building independent services in parallel.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filePath := configFile(cmd)
+		noCache, _ := cmd.Flags().GetBool("no-cache")
+
+		project, err := config.LoadFile(filePath)
+		if err != nil {
+			log.Fatalf("Parse Compose failed: %v", err)
+		}
+
+		profileFlags, _ := cmd.Flags().GetStringSlice("profile")
+		project, err = project.ApplyProfiles(config.ActiveProfiles(profileFlags), args)
+		if err != nil {
+			log.Fatalf("Apply profiles failed: %v", err)
+		}
+
+		selected, err := project.WithServices(args, false)
+		if err != nil {
+			log.Fatalf("Select services failed: %v", err)
+		}
+
+		failed, err := buildImages(project, selected, true, noCache)
+		if err != nil {
+			log.Fatalf("Build failed: %v", err)
+		}
+		if len(failed) > 0 {
+			os.Exit(1)
+		}
+		fmt.Println("✅ All images built successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(buildCmd)
+	buildCmd.Flags().StringP("file", "f", "", "Compose file (searched in the current and parent directories by default)")
+	buildCmd.Flags().Bool("no-cache", false, "Do not use cache when building images")
+}
+
+// buildImages 建置 selected 中具有 build 區段的服務：依啟動層級逐層進行，同一層的服務彼此獨立，平行建置。
+// force 為 false 時略過映像檔已存在的服務；回傳建置失敗的服務
+func buildImages(project, selected config.RoverCompose, force, noCache bool) (map[string]error, error) {
+	levels, err := config.GetServiceStartupLevels(project.Services)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := make(map[string]error)
+	built := make(map[string]bool)
+	for _, level := range levels {
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+
+		for _, name := range level {
+			service, ok := selected.Services[name]
+			if !ok || service.Build == nil {
+				continue
+			}
+
+			// 多個服務共用同一映像檔時只建置一次
+			image := service.ImageName(project.Name)
+			if built[image] {
+				continue
+			}
+			built[image] = true
+
+			// pull_policy: build 時一律重新建置
+			rebuild := force || service.PullPolicy == config.PullPolicyBuild
+			if !rebuild && imageExists(image) {
+				continue
+			}
+
+			wg.Add(1)
+			go func(service config.Service, image string) {
+				defer wg.Done()
+				err := buildImageWithPodman(project, service, image, noCache)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					log.Printf("Image %s build failed: %v", image, err)
+					failed[service.Name] = err
+					return
+				}
+				fmt.Printf("🔨 Image %s built for %s\n", image, service.Name)
+			}(service, image)
+		}
+		wg.Wait()
+	}
+	return failed, nil
+}
+
+// buildImageWithPodman 以 Podman 建置單一服務的映像檔
+func buildImageWithPodman(project config.RoverCompose, service config.Service, image string, noCache bool) error {
+	build := service.Build
+	context := project.BuildContext(build)
+	args := []string{"build", "-t", image, "-f", build.DockerfilePath(context)}
+
+	for _, key := range sortedStringKeys(build.Args) {
+		args = append(args, "--build-arg", fmt.Sprintf("%s=%s", key, build.Args[key]))
+	}
+	for _, key := range sortedStringKeys(build.Labels) {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", key, build.Labels[key]))
+	}
+	if build.Target != "" {
+		args = append(args, "--target", build.Target)
+	}
+	for _, from := range build.CacheFrom {
+		args = append(args, "--cache-from", from)
+	}
+	if noCache {
+		args = append(args, "--no-cache")
+	}
+	args = append(args, context)
+
+	// 平行建置時輸出會交錯，失敗時才輸出完整紀錄
+	cmd := exec.Command("podman", args...)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%v\n%s", err, output.String())
+	}
+	return nil
+}
+
+// imageExists 檢查本機是否已有映像檔
+func imageExists(image string) bool {
+	return exec.Command("podman", "image", "exists", image).Run() == nil
+}
+
+// localImageID 回傳本機映像檔的 ID
+func localImageID(image string) (string, error) {
+	output, err := exec.Command("podman", "image", "inspect", "--format", "{{.Id}}", image).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
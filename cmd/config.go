@@ -1,7 +1,15 @@
 package cmd
 
 import (
+	"fmt"
+	"log"
+	"os"
 	"runtime"
+	"sort"
+
+	"github.com/vvvdwbvvv/rover/internal/config"
+
+	"github.com/spf13/cobra"
 )
 
 var containerRuntime string
@@ -13,3 +21,115 @@ func init() {
 		containerRuntime = "runc"
 	}
 }
+
+// configCmd 輸出內插、profile 過濾與預設值處理後的完整專案
+var configCmd = &cobra.Command{
+	Use:   "config [service...]",
+	Short: "Print the fully resolved project",
+	Long:  `Print the project Rover derives from the compose file after interpolation, profile filtering and defaulting, or list only its services, images, volumes or per-service config hashes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filePath := configFile(cmd)
+		format, _ := cmd.Flags().GetString("format")
+		listServices, _ := cmd.Flags().GetBool("services")
+		listImages, _ := cmd.Flags().GetBool("images")
+		listVolumes, _ := cmd.Flags().GetBool("volumes")
+		listHashes, _ := cmd.Flags().GetBool("hash")
+
+		project, err := config.LoadFile(filePath)
+		if err != nil {
+			log.Fatalf("Parse Compose failed: %v", err)
+		}
+
+		// 設定雜湊與 apply 相同，以 rover.lock 釘選後的映像檔計算
+		if listHashes {
+			project, _, err = lockedImages(project)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		profileFlags, _ := cmd.Flags().GetStringSlice("profile")
+		project, err = project.ApplyProfiles(config.ActiveProfiles(profileFlags), args)
+		if err != nil {
+			log.Fatalf("Apply profiles failed: %v", err)
+		}
+		full := project
+		project, err = project.WithServices(args, true)
+		if err != nil {
+			log.Fatalf("Select services failed: %v", err)
+		}
+
+		order, err := config.GetServiceStartupOrder(project.Services)
+		if err != nil {
+			log.Fatalf("Resolve startup order failed: %v", err)
+		}
+
+		switch {
+		case listServices:
+			for _, name := range order {
+				fmt.Println(name)
+			}
+		case listImages:
+			for _, name := range order {
+				fmt.Println(project.Services[name].ImageName(project.Name))
+			}
+		case listVolumes:
+			names := make([]string, 0, len(project.Volumes))
+			for name := range project.Volumes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Println(name)
+			}
+		case listHashes:
+			// 與 apply 相同，雜湊包含 secrets / configs 內容的雜湊，可能需要輸入 secret store 的密語
+			secrets := newSecretMounts(full)
+			for _, name := range order {
+				digest, err := secrets.contentDigest(project.Services[name])
+				if err != nil {
+					log.Fatalf("Hash service %s failed: %v", name, err)
+				}
+				hash, err := serviceConfigHash(full, project.Services[name], digest)
+				if err != nil {
+					log.Fatalf("Hash service %s failed: %v", name, err)
+				}
+				fmt.Printf("%s %s\n", name, hash)
+			}
+		default:
+			data, err := config.Marshal(project, format)
+			if err != nil {
+				log.Fatalf("Render project failed: %v", err)
+			}
+			fmt.Print(string(data))
+		}
+	},
+}
+
+// configFile 回傳 -f 指定的設定檔，未指定時從目前目錄往上層尋找
+func configFile(cmd *cobra.Command) string {
+	filePath, _ := cmd.Flags().GetString("file")
+	if filePath != "" {
+		return filePath
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+	filePath, err = config.FindConfigFile(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return filePath
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.Flags().StringP("file", "f", "", "Compose file (searched in the current and parent directories by default)")
+	configCmd.Flags().String("format", config.FormatYAML, "Output format: yaml, json or toml")
+	configCmd.Flags().Bool("services", false, "Print the service names, one per line")
+	configCmd.Flags().Bool("images", false, "Print the image names, one per line")
+	configCmd.Flags().Bool("volumes", false, "Print the volume names, one per line")
+	configCmd.Flags().Bool("hash", false, "Print the config hash of each service")
+}
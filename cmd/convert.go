@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/vvvdwbvvv/rover/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// convertCmd 在各種 compose 格式之間轉換
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert a compose file between YAML, TOML and JSON",
+	Long:  `Read docker-compose YAML or rover-compose YAML/TOML/JSON and write the normalized project in the requested format, keeping field order and comments where the format allows.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filePath := configFile(cmd)
+		output, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+
+		if format == "" {
+			format = config.FormatFromPath(output)
+		}
+
+		data, err := config.Convert(filePath, format)
+		if err != nil {
+			log.Fatalf("Convert failed: %v", err)
+		}
+
+		if output == "" {
+			fmt.Print(string(data))
+			return
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			log.Fatalf("Write %s failed: %v", output, err)
+		}
+		fmt.Printf("✅ Converted %s to %s\n", filePath, output)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+	convertCmd.Flags().StringP("file", "f", "", "Compose file (searched in the current and parent directories by default)")
+	convertCmd.Flags().StringP("output", "o", "", "Output file (defaults to stdout)")
+	convertCmd.Flags().String("format", "", "Output format: yaml, toml or json (defaults to the output file extension)")
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vvvdwbvvv/rover/internal/config"
+	"github.com/vvvdwbvvv/rover/internal/container"
+)
+
+// 等待依賴達到 service_healthy 的最長時間
+const dependencyTimeout = 60 * time.Second
+
+// atomic 部署中沒有 healthcheck 的容器啟動後觀察的時間，期間內結束或失敗的容器視為未就緒
+const readinessWindow = 5 * time.Second
+
+// waitForDependencies 依照 depends_on 的 condition 等待本次啟動的依賴就緒；
+// required: false 的依賴未就緒時只記錄警告
+func waitForDependencies(service config.Service, started map[string]bool) error {
+	for _, dep := range service.DependsOn.Names() {
+		dependency := service.DependsOn[dep]
+		if !started[dep] {
+			continue
+		}
+
+		var err error
+		switch dependency.Condition {
+		case config.ServiceConditionHealthy:
+			fmt.Printf("Wait for dependency %s to be healthy...\n", dep)
+			err = waitForHealthy(dep, dependencyTimeout)
+		case config.ServiceConditionCompletedSuccessfully:
+			fmt.Printf("Wait for dependency %s to complete...\n", dep)
+			err = waitForCompletion(dep)
+		}
+
+		if err != nil {
+			if !dependency.Required {
+				log.Printf("Optional dependency %s of %s is not ready: %v", dep, service.Name, err)
+				continue
+			}
+			return fmt.Errorf("dependency %s is not ready: %w", dep, err)
+		}
+	}
+	return nil
+}
+
+// waitForReady 等待 atomic 部署中新啟動的容器就緒：有 healthcheck 時等待 healthy，
+// 沒有時觀察 readinessWindow，之後由呼叫者重新 inspect 確認容器沒有失敗
+func waitForReady(containerName string) error {
+	inspection, err := container.Inspect(containerName)
+	if err != nil {
+		return fmt.Errorf("inspect failed: %v", err)
+	}
+	if inspection.State.Health == nil || inspection.State.Health.Status == "" {
+		fmt.Printf("Watch %s for %s...\n", containerName, readinessWindow)
+		time.Sleep(readinessWindow)
+		return nil
+	}
+	fmt.Printf("Wait for %s to be healthy...\n", containerName)
+	return waitForHealthy(containerName, dependencyTimeout)
+}
+
+// waitForHealthy 輪詢容器的 healthcheck 狀態直到 healthy
+func waitForHealthy(containerName string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		output, err := exec.Command("podman", "inspect", "--format", "{{.State.Health.Status}}", containerName).Output()
+		if err != nil {
+			return fmt.Errorf("inspect failed: %v", err)
+		}
+
+		switch status := strings.TrimSpace(string(output)); status {
+		case "healthy":
+			return nil
+		case "unhealthy":
+			return errors.New("container is unhealthy")
+		case "", "<no value>":
+			return errors.New("container has no healthcheck configured")
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s", timeout)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// waitForCompletion 等待容器結束並確認 exit code 為 0
+func waitForCompletion(containerName string) error {
+	cmd := exec.Command("podman", "wait", containerName)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("wait failed: %s", stderr.String())
+	}
+
+	code, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return fmt.Errorf("unexpected exit code %q", strings.TrimSpace(string(output)))
+	}
+	if code != 0 {
+		return fmt.Errorf("exited with code %d", code)
+	}
+	return nil
+}
+
+// restartDependents 重啟本次未重建、但對重建的依賴設定 restart: true 的服務，回傳重啟成功的服務
+func restartDependents(project config.RoverCompose, order []string, recreated map[string]bool) []string {
+	var restarted []string
+	for _, name := range order {
+		if recreated[name] {
+			continue
+		}
+
+		service := project.Services[name]
+		for _, dep := range service.DependsOn.Names() {
+			if !recreated[dep] || !service.DependsOn[dep].Restart {
+				continue
+			}
+			if !isContainerRunning(name) {
+				break
+			}
+
+			fmt.Printf("Restarting %s because dependency %s was recreated...\n", name, dep)
+			if err := exec.Command("podman", "restart", name).Run(); err != nil {
+				log.Printf("Container %s restart failed: %v", name, err)
+			} else {
+				restarted = append(restarted, name)
+			}
+			break
+		}
+	}
+	return restarted
+}
@@ -0,0 +1,391 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/vvvdwbvvv/rover/internal/config"
+	"github.com/vvvdwbvvv/rover/internal/container"
+	"github.com/vvvdwbvvv/rover/pkg/model"
+	"github.com/vvvdwbvvv/rover/pkg/storage"
+)
+
+// deployment 一次部署的內容；apply 由設定檔產生，rollback 由先前的部署版本產生
+type deployment struct {
+	project       config.RoverCompose // 套用 profiles 後的完整專案，依賴圖以此計算
+	selected      config.RoverCompose // 本次部署的服務
+	configFile    string
+	profiles      []string
+	digests       map[string]string // 服務需使用的映像檔 digest；rollback 時為目標版本的 digest
+	forceBuild    bool
+	forceRecreate bool // 設定與映像檔未變更的容器也重新建立
+	atomic        bool // 任一服務失敗時還原本次部署前的容器
+	rollbackOf    int
+}
+
+// deployResult 部署的結果
+type deployResult struct {
+	revision   *model.Revision
+	started    map[string]bool // 重新建立且就緒的容器
+	unchanged  map[string]bool // 設定與映像檔未變更而保留的容器
+	failed     map[string]bool // 無法啟動或未就緒的服務
+	rolledBack bool            // atomic 部署失敗並已還原
+	restored   []string        // 還原的舊容器
+}
+
+// deploy 依 depends_on 順序重新建立設定或映像檔有變更的服務容器，並將結果存儲為新的部署版本
+func deploy(db storage.Store, d deployment) deployResult {
+	project, selected := d.project, d.selected
+
+	// 先處理中斷的 atomic 部署留下的舊容器，避免與本次部署的容器名稱衝突
+	recoverLeftovers(db, podmanCLI{}, project.Name)
+
+	// 依賴圖以完整專案計算，部分 apply 時只啟動選取的服務，確保依賴一定先啟動
+	order, err := config.GetServiceStartupOrder(project.Services)
+	if err != nil {
+		log.Fatalf("Resolve startup order failed: %v", err)
+	}
+
+	// 建置具有 build 區段的服務映像檔；--build 時一律重新建置
+	buildFailed, err := buildImages(project, selected, d.forceBuild, false)
+	if err != nil {
+		log.Fatalf("Build images failed: %v", err)
+	}
+
+	// 啟動任何容器前先平行下載所需的映像檔
+	pinned := pinDigests(selected, d.digests)
+	pullFailed := pullImages(pinned)
+
+	// 設定雜湊在取代任何容器前計算，無法計算的服務視為失敗，不會在 atomic 部署途中中止程序；
+	// 雜湊以未釘選 rollback digest 的服務計算，rollback 時才能與原本的容器比較
+	secrets := newSecretMounts(project)
+	hashes, hashFailed := serviceConfigHashes(project, selected, order, secrets)
+
+	// 每次部署為一個版本，版本號記錄在容器的 label 上
+	revision := newRevision(db, project.Name)
+	revision.RollbackOf = d.rollbackOf
+	deployed := make(map[string]model.ContainerState)
+
+	// atomic 部署時保留被取代的舊容器，失敗時還原
+	var tx *transaction
+	if d.atomic {
+		tx = newTransaction(db, podmanCLI{}, project.Name)
+	}
+
+	// 啟動容器（按照 depends_on 順序）
+	started := make(map[string]bool)
+	unchanged := make(map[string]bool)
+	failed := make(map[string]bool)
+	for _, name := range order {
+		service, ok := selected.Services[name]
+		if !ok {
+			continue
+		}
+		if d.atomic && len(failed) > 0 {
+			break
+		}
+		if err, ok := hashFailed[name]; ok {
+			log.Printf("Container %s skipped: config hash failed: %v", service.Name, err)
+			failed[service.Name] = true
+			continue
+		}
+		hash := hashes[name]
+		service.Image = pinned.Services[name].ImageName(project.Name)
+
+		// secrets / configs 內容的雜湊包含在設定雜湊中，內容變更的服務才重新建立
+		if !d.forceRecreate {
+			if state, ok := unchangedContainer(db, name, hash, service.Image); ok {
+				fmt.Printf("Container %s is up to date\n", name)
+				unchanged[name] = true
+				deployed[name] = state
+				continue
+			}
+		}
+
+		if _, ok := buildFailed[name]; ok {
+			log.Printf("Container %s skipped: image build failed", service.Name)
+			failed[service.Name] = true
+			continue
+		}
+
+		if _, ok := pullFailed[name]; ok {
+			log.Printf("Container %s skipped: image pull failed", service.Name)
+			failed[service.Name] = true
+			continue
+		}
+
+		if dep := failedDependency(service, failed); dep != "" {
+			log.Printf("Container %s skipped: dependency %s failed to start", service.Name, dep)
+			failed[service.Name] = true
+			continue
+		}
+
+		if err := waitForDependencies(service, started); err != nil {
+			log.Printf("Container %s skipped: %v", service.Name, err)
+			failed[service.Name] = true
+			continue
+		}
+
+		mounts, err := secrets.mountArgs(service)
+		if err != nil {
+			log.Printf("Container %s skipped: %v", service.Name, err)
+			failed[service.Name] = true
+			continue
+		}
+
+		if tx != nil {
+			if err := tx.replace(name); err != nil {
+				log.Printf("Container %s skipped: %v", service.Name, err)
+				failed[service.Name] = true
+				continue
+			}
+		}
+
+		// 啟動前先記錄為 starting，讓 ps 與 down 看到進行中的容器
+		state := startingContainerState(db, project.Name, revision.Number, hash, service)
+		if err := startContainerWithPodman(project, service, mounts, container.Labels(*state, d.configFile)); err != nil {
+			log.Printf("Container %s launch failed: %v", service.Name, err)
+			failed[service.Name] = true
+			transitionContainer(db, state, model.StatusFailed)
+			deployed[service.Name] = *state
+			continue
+		}
+
+		// atomic 部署時等待容器就緒才視為啟動成功，逾時或不健康時視為失敗並觸發還原
+		var notReady error
+		if d.atomic {
+			notReady = waitForReady(name)
+		}
+
+		// 執行環境的實際狀態由 podman inspect 補上；啟動後立即結束或不健康的容器視為未就緒
+		recorded := recordContainer(db, *state)
+		deployed[service.Name] = recorded
+		if notReady != nil {
+			log.Printf("Container %s is not ready: %v", service.Name, notReady)
+			failed[service.Name] = true
+			continue
+		}
+		if recorded.Status == model.StatusFailed || recorded.Status == model.StatusUnhealthy {
+			log.Printf("Container %s is not ready: %s (exit code %d)", service.Name, recorded.Status, recorded.ExitCode)
+			failed[service.Name] = true
+			continue
+		}
+		started[service.Name] = true
+		recordEvent(db, project.Name, name, model.EventDeployed, fmt.Sprintf("revision %d", revision.Number))
+	}
+
+	for _, name := range sortedKeys(failed) {
+		reason := "skipped"
+		if state, ok := deployed[name]; ok {
+			reason = string(state.Status)
+		}
+		recordEvent(db, project.Name, name, model.EventDeployFailed, fmt.Sprintf("revision %d: %s", revision.Number, reason))
+	}
+
+	result := deployResult{started: started, unchanged: unchanged, failed: failed}
+	running := make(map[string]bool)
+	for name := range unchanged {
+		running[name] = true
+	}
+	switch {
+	case tx != nil && len(failed) > 0:
+		// 刪除本次建立的容器並還原舊容器
+		result.rolledBack = true
+		result.restored = tx.rollback()
+		for _, name := range result.restored {
+			running[name] = true
+		}
+	default:
+		if tx != nil {
+			tx.commit()
+		}
+		for name := range started {
+			running[name] = true
+		}
+		// 依賴被重建時，重啟設定了 restart: true 的其他服務
+		for _, name := range restartDependents(project, order, started) {
+			refreshContainer(db, name)
+		}
+	}
+
+	// 記錄本次部署使用的設定檔與 profiles，供 ps / down 使用
+	if err := db.SaveProject(mergeProjectState(db, model.ProjectState{
+		Name:       project.Name,
+		ConfigFile: d.configFile,
+		Profiles:   d.profiles,
+		Secrets:    secrets.Names(),
+		UpdatedAt:  time.Now(),
+	}, selected, running)); err != nil {
+		log.Printf("Unable to save project %s: %v", project.Name, err)
+	}
+
+	revision.ConfigFile = d.configFile
+	revision.Profiles = d.profiles
+	switch {
+	case result.rolledBack:
+		revision.Outcome = model.OutcomeRolledBack
+	case len(failed) > 0:
+		revision.Outcome = model.OutcomeFailed
+	default:
+		revision.Outcome = model.OutcomeSucceeded
+	}
+	if err := recordDeployment(revision, project, selected, order, hashes, deployed); err != nil {
+		log.Printf("Unable to record revision %d: %v", revision.Number, err)
+	}
+	saveRevision(db, revision)
+	if d.rollbackOf > 0 {
+		recordEvent(db, project.Name, "", model.EventRollback,
+			fmt.Sprintf("rollback to revision %d recorded as revision %d: %s", d.rollbackOf, revision.Number, revision.Outcome))
+	}
+	result.revision = revision
+	return result
+}
+
+// printSummary 輸出部署結果；有服務失敗時回傳 false
+func (r deployResult) printSummary() bool {
+	summary, ok := r.summary()
+	fmt.Print(summary)
+	return ok
+}
+
+// summary 回傳部署結果的說明；有服務失敗時 ok 為 false
+func (r deployResult) summary() (summary string, ok bool) {
+	var b strings.Builder
+	if len(r.failed) == 0 {
+		fmt.Fprintf(&b, "✅ All containers started successfully (revision %d: %d recreated, %d unchanged)\n",
+			r.revision.Number, len(r.started), len(r.unchanged))
+		return b.String(), true
+	}
+
+	fmt.Fprintf(&b, "❌ Revision %d failed: %s\n", r.revision.Number, strings.Join(sortedKeys(r.failed), ", "))
+	if r.rolledBack {
+		if len(r.restored) == 0 {
+			fmt.Fprintln(&b, "↩️  Rolled back: new containers removed, no previous containers to restore")
+		} else {
+			fmt.Fprintf(&b, "↩️  Rolled back: new containers removed, restored %s\n", strings.Join(r.restored, ", "))
+		}
+		return b.String(), false
+	}
+	if len(r.started) > 0 {
+		fmt.Fprintf(&b, "🔹 Started: %s\n", strings.Join(sortedKeys(r.started), ", "))
+	}
+	if len(r.unchanged) > 0 {
+		fmt.Fprintf(&b, "🔹 Unchanged: %s\n", strings.Join(sortedKeys(r.unchanged), ", "))
+	}
+	return b.String(), false
+}
+
+// unchangedContainer 服務的容器仍以相同設定與映像檔執行時回傳其記錄，不需重新建立
+func unchangedContainer(db storage.Store, name, hash, image string) (model.ContainerState, bool) {
+	state, err := db.GetContainer(name)
+	if err != nil || state.ConfigHash != hash {
+		return model.ContainerState{}, false
+	}
+	inspection, err := container.Inspect(name)
+	if err != nil {
+		return model.ContainerState{}, false
+	}
+	// 狀態在 Rover 之外有非預期的變更時重新建立
+	if err := inspection.Apply(state); err != nil || !state.Status.Active() {
+		return model.ContainerState{}, false
+	}
+	id, err := localImageID(image)
+	if err != nil || id != inspection.ImageID {
+		return model.ContainerState{}, false
+	}
+	return *state, true
+}
+
+// serviceConfigHash 回傳容器 label、部署版本與 `rover config --hash` 共用的設定雜湊：
+// 以補上預設映像檔名稱後的服務計算；rover.lock 的 digest 應在呼叫前由 pinImages 套用。
+// project 為套用 profiles 後的完整專案，links 的別名設定在被連結的容器上，別名變更時需重新建立。
+// contentDigest 為 secretMounts.contentDigest 回傳的 secrets / configs 內容雜湊，沒有掛載時為空字串
+func serviceConfigHash(project config.RoverCompose, service config.Service, contentDigest string) (string, error) {
+	service.Image = service.ImageName(project.Name)
+	hash, err := config.ServiceHash(service)
+	if err != nil {
+		return "", err
+	}
+	if aliases := project.LinkAliases(service.Name); len(aliases) > 0 {
+		sum := sha256.Sum256([]byte(hash + " " + strings.Join(aliases, ",")))
+		hash = hex.EncodeToString(sum[:])
+	}
+	if contentDigest != "" {
+		sum := sha256.Sum256([]byte(hash + " " + contentDigest))
+		hash = hex.EncodeToString(sum[:])
+	}
+	return hash, nil
+}
+
+// serviceConfigHashes 依啟動順序計算選取服務的設定雜湊，包含其 secrets / configs 內容的雜湊；
+// 無法計算或無法讀取內容的服務記錄在 failed 中
+func serviceConfigHashes(project, selected config.RoverCompose, order []string, secrets *secretMounts) (map[string]string, map[string]error) {
+	hashes := make(map[string]string)
+	failed := make(map[string]error)
+	for _, name := range order {
+		service, ok := selected.Services[name]
+		if !ok {
+			continue
+		}
+		digest, err := secrets.contentDigest(service)
+		if err != nil {
+			failed[name] = err
+			continue
+		}
+		hash, err := serviceConfigHash(project, service, digest)
+		if err != nil {
+			failed[name] = err
+			continue
+		}
+		hashes[name] = hash
+	}
+	return hashes, failed
+}
+
+// pinDigests 回傳將映像檔替換為指定 digest 的專案副本；具有 build 區段的服務不替換
+func pinDigests(project config.RoverCompose, digests map[string]string) config.RoverCompose {
+	if len(digests) == 0 {
+		return project
+	}
+	services := make(map[string]config.Service, len(project.Services))
+	for name, service := range project.Services {
+		if digest := digests[name]; digest != "" && service.Image != "" && service.Build == nil {
+			service.Image = config.PinnedImage(service.Image, digest)
+		}
+		services[name] = service
+	}
+	project.Services = services
+	return project
+}
+
+// recordDeployment 在部署版本中記錄解析後的專案，以及選取服務的設定雜湊、映像檔 digest 與部署結果
+func recordDeployment(revision *model.Revision, project, selected config.RoverCompose, order []string, hashes map[string]string, deployed map[string]model.ContainerState) error {
+	compose, err := json.Marshal(project)
+	if err != nil {
+		return err
+	}
+	revision.Compose = compose
+
+	for _, name := range order {
+		service, ok := selected.Services[name]
+		if !ok {
+			continue
+		}
+		entry := model.ServiceRevision{
+			Name:       name,
+			ConfigHash: hashes[name],
+			Image:      service.ImageName(project.Name),
+		}
+		if state, ok := deployed[name]; ok {
+			entry.ImageDigest = state.ImageDigest
+			entry.Status = state.Status
+		}
+		revision.Services = append(revision.Services, entry)
+	}
+	return nil
+}
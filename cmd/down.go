@@ -5,7 +5,10 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"sort"
 
+	"github.com/vvvdwbvvv/rover/internal/config"
+	"github.com/vvvdwbvvv/rover/pkg/model"
 	"github.com/vvvdwbvvv/rover/pkg/storage"
 
 	"github.com/spf13/cobra"
@@ -20,7 +23,7 @@ var downCmd = &cobra.Command{
 		stopRoverContainers, _ := cmd.Flags().GetBool("last")
 
 		if stopRoverContainers {
-			stopRoverManagedContainers()
+			stopRoverManagedContainers(cmd)
 		} else {
 			stopAllContainers()
 		}
@@ -48,12 +51,9 @@ func stopAllContainers() {
 	fmt.Println("✅ All containers have been stopped and removed.")
 }
 
-// 停止並刪除 Rover 啟動的容器
-func stopRoverManagedContainers() {
-	db, err := storage.NewBoltDB("rover.db")
-	if err != nil {
-		log.Fatal(err)
-	}
+// 停止並刪除 Rover 啟動的容器；預設沿用 apply 時的 profiles，也可用 --profile 只停止部分服務
+func stopRoverManagedContainers(cmd *cobra.Command) {
+	db := openStore(cmd)
 	defer db.Close()
 
 	containers, err := db.GetContainers()
@@ -66,16 +66,97 @@ func stopRoverManagedContainers() {
 		return
 	}
 
+	projects, err := db.GetProjects()
+	if err != nil {
+		log.Fatal(err)
+	}
+	projectByName := make(map[string]*model.ProjectState)
+	for i := range projects {
+		projectByName[projects[i].Name] = &projects[i]
+	}
+
+	// 依照 depends_on 的反向順序停止，讓依賴者先於被依賴者停止
+	sortByShutdownOrder(db, containers, projects)
+
+	remaining := make(map[string][]string)
 	for _, c := range containers {
+		profiles := projectProfiles(cmd, projectByName[c.Project])
+		if !config.ProfileEnabled(c.Profiles, profiles) {
+			remaining[c.Project] = append(remaining[c.Project], c.Name)
+			continue
+		}
+
 		fmt.Printf("🛑 Stopping container %s...\n", c.Name)
+		transitionContainer(db, &c, model.StatusRemoving)
 		exec.Command("podman", "stop", c.Name).Run()
-		exec.Command("podman", "rm", c.Name).Run()
-		db.DeleteContainer(c.Name) // 從 BoltDB 刪除記錄
+		if err := exec.Command("podman", "rm", c.Name).Run(); err != nil && isContainerRunning(c.Name) {
+			log.Printf("Container %s removal failed: %v", c.Name, err)
+			transitionContainer(db, &c, model.StatusFailed)
+			remaining[c.Project] = append(remaining[c.Project], c.Name)
+			continue
+		}
+		transitionContainer(db, &c, model.StatusRemoved)
+		db.DeleteContainer(c.Name) // 移除後刪除記錄
+		recordEvent(db, c.Project, c.Name, model.EventStopped, "removed by rover down")
+	}
+
+	// 更新專案記錄：容器全數移除則刪除專案
+	for _, project := range projects {
+		if names, ok := remaining[project.Name]; ok {
+			project.Services = names
+			db.SaveProject(project)
+		} else {
+			// 專案的容器全數移除後，一併刪除其 secrets / configs
+			removePodmanSecrets(project.Secrets)
+			db.DeleteProject(project.Name)
+		}
 	}
 
 	fmt.Println("✅ Rover-managed containers have been stopped and removed.")
 }
 
+// sortByShutdownOrder 以專案最新部署版本的設定計算停止順序並排序容器；無法取得設定時維持原順序
+func sortByShutdownOrder(db storage.Store, containers []model.ContainerState, projects []model.ProjectState) {
+	rank := make(map[string]int)
+	for _, project := range projects {
+		compose, err := deployedCompose(db, project)
+		if err != nil {
+			log.Printf("Unable to determine the shutdown order of project %s: %v", project.Name, err)
+			continue
+		}
+		order, err := config.GetServiceShutdownOrder(compose.Services)
+		if err != nil {
+			continue
+		}
+		for i, name := range order {
+			rank[project.Name+"/"+name] = i
+		}
+	}
+
+	sort.SliceStable(containers, func(i, j int) bool {
+		if containers[i].Project != containers[j].Project {
+			return containers[i].Project < containers[j].Project
+		}
+		return rank[containers[i].Project+"/"+containers[i].Name] < rank[containers[j].Project+"/"+containers[j].Name]
+	})
+}
+
+// deployedCompose 回傳專案最新部署版本中存儲的設定，與設定檔目前的內容無關；
+// 沒有部署版本或版本未存儲設定（例如舊版 rover 建立的狀態）時才讀取設定檔
+func deployedCompose(db storage.Store, project model.ProjectState) (config.RoverCompose, error) {
+	revisions, err := db.GetRevisions(project.Name)
+	if err != nil {
+		return config.RoverCompose{}, err
+	}
+	if n := len(revisions); n > 0 && len(revisions[n-1].Compose) > 0 {
+		return revisionCompose(&revisions[n-1])
+	}
+	if project.ConfigFile == "" {
+		return config.RoverCompose{}, fmt.Errorf("project has no revision and no config file")
+	}
+	return config.LoadFile(project.ConfigFile)
+}
+
 func init() {
 	downCmd.Flags().BoolP("last", "l", false, "Stop only Rover-managed containers")
 	rootCmd.AddCommand(downCmd)
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vvvdwbvvv/rover/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// graphCmd 輸出服務依賴圖
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Print the service dependency graph",
+	Long:  `Print the dependency graph built from depends_on, links, network_mode: service:x and volumes_from as Graphviz DOT, Mermaid or an ASCII tree, annotated with conditions and profiles.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filePath := configFile(cmd)
+		format, _ := cmd.Flags().GetString("format")
+
+		project, err := config.LoadFile(filePath)
+		if err != nil {
+			log.Fatalf("Parse Compose failed: %v", err)
+		}
+
+		// 未指定 profile 時顯示所有服務
+		profileFlags, _ := cmd.Flags().GetStringSlice("profile")
+		profiles := config.ActiveProfiles(profileFlags)
+		if len(profiles) == 0 {
+			profiles = []string{"*"}
+		}
+		project, err = project.ApplyProfiles(profiles, nil)
+		if err != nil {
+			log.Fatalf("Apply profiles failed: %v", err)
+		}
+
+		switch format {
+		case "dot":
+			fmt.Print(renderDOT(project))
+		case "mermaid":
+			fmt.Print(renderMermaid(project))
+		case "tree":
+			fmt.Print(renderTree(project))
+		default:
+			log.Fatalf("Unsupported graph format %q (use dot, mermaid or tree)", format)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.Flags().StringP("file", "f", "", "Compose file (searched in the current and parent directories by default)")
+	graphCmd.Flags().String("format", "tree", "Output format: dot, mermaid or tree")
+}
+
+// serviceNames 回傳依名稱排序的服務
+func serviceNames(project config.RoverCompose) []string {
+	names := make([]string, 0, len(project.Services))
+	for name := range project.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// edgeLabel 依賴的說明：depends_on 顯示 condition 與旗標，其餘顯示來源
+func edgeLabel(edge config.DependencyEdge) string {
+	if edge.Kind != config.DependencyDependsOn {
+		return edge.Kind
+	}
+	parts := []string{edge.Condition}
+	if !edge.Required {
+		parts = append(parts, "optional")
+	}
+	if edge.Restart {
+		parts = append(parts, "restart")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func renderDOT(project config.RoverCompose) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", project.Name)
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, name := range serviceNames(project) {
+		label := name
+		if profiles := project.Services[name].Profiles; len(profiles) > 0 {
+			label += "\\n[" + strings.Join(profiles, ", ") + "]"
+			fmt.Fprintf(&b, "  %q [label=\"%s\", style=dashed];\n", name, label)
+			continue
+		}
+		fmt.Fprintf(&b, "  %q;\n", name)
+	}
+
+	for _, edge := range project.DependencyGraph() {
+		style := ""
+		if edge.Kind != config.DependencyDependsOn || !edge.Required {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %q -> %q [label=%q%s];\n", edge.From, edge.To, edgeLabel(edge), style)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+var mermaidUnsafe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func mermaidID(name string) string {
+	return mermaidUnsafe.ReplaceAllString(name, "_")
+}
+
+func renderMermaid(project config.RoverCompose) string {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+
+	for _, name := range serviceNames(project) {
+		label := name
+		if profiles := project.Services[name].Profiles; len(profiles) > 0 {
+			label += "<br/>profiles: " + strings.Join(profiles, ", ")
+		}
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", mermaidID(name), label)
+	}
+
+	for _, edge := range project.DependencyGraph() {
+		arrow := "-->"
+		if edge.Kind != config.DependencyDependsOn || !edge.Required {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", mermaidID(edge.From), arrow, edgeLabel(edge), mermaidID(edge.To))
+	}
+	return b.String()
+}
+
+// renderTree 以沒有被其他服務依賴的服務為根，逐層列出依賴
+func renderTree(project config.RoverCompose) string {
+	dependents := make(map[string]bool)
+	for _, edge := range project.DependencyGraph() {
+		dependents[edge.To] = true
+	}
+
+	var roots []string
+	for _, name := range serviceNames(project) {
+		if !dependents[name] {
+			roots = append(roots, name)
+		}
+	}
+	// 整個專案都在循環中時沒有根，改為列出所有服務
+	if len(roots) == 0 {
+		roots = serviceNames(project)
+	}
+
+	var b strings.Builder
+	for _, root := range roots {
+		b.WriteString(treeNode(project, root) + "\n")
+		writeTreeChildren(&b, project, root, "", map[string]bool{root: true})
+	}
+	return b.String()
+}
+
+func treeNode(project config.RoverCompose, name string) string {
+	service, exists := project.Services[name]
+	if !exists {
+		return name + " (missing)"
+	}
+	if len(service.Profiles) > 0 {
+		return fmt.Sprintf("%s [profiles: %s]", name, strings.Join(service.Profiles, ", "))
+	}
+	return name
+}
+
+func writeTreeChildren(b *strings.Builder, project config.RoverCompose, name, prefix string, path map[string]bool) {
+	edges := project.Services[name].Dependencies()
+	for i, edge := range edges {
+		branch, next := "├── ", "│   "
+		if i == len(edges)-1 {
+			branch, next = "└── ", "    "
+		}
+
+		line := fmt.Sprintf("%s%s%s (%s)", prefix, branch, treeNode(project, edge.To), edgeLabel(edge))
+		if path[edge.To] {
+			b.WriteString(line + " ↺ cycle\n")
+			continue
+		}
+		b.WriteString(line + "\n")
+
+		path[edge.To] = true
+		writeTreeChildren(b, project, edge.To, prefix+next, path)
+		delete(path, edge.To)
+	}
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vvvdwbvvv/rover/internal/config"
+	"github.com/vvvdwbvvv/rover/pkg/model"
+	"github.com/vvvdwbvvv/rover/pkg/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// historyCmd 列出專案的部署版本
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the deployment revisions of a project",
+	Long:  `List every apply of the project as an immutable revision. The project is taken from --project or from the compose file.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openStore(cmd)
+		defer db.Close()
+
+		project := historyProject(cmd)
+		revisions, err := db.GetRevisions(project)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(revisions) == 0 {
+			fmt.Printf("🔹 Project %s has no revisions yet.\n", project)
+			return
+		}
+
+		fmt.Printf("📜 Revisions of project %s:\n", project)
+		for _, revision := range revisions {
+			names := make([]string, 0, len(revision.Services))
+			for _, s := range revision.Services {
+				names = append(names, s.Name)
+			}
+			line := fmt.Sprintf("%s #%d  %s  %s  %s  %s", outcomeIcon(revision.Outcome), revision.Number,
+				revision.CreatedAt.Format(time.DateTime), revision.User, revision.Outcome, strings.Join(names, ", "))
+			if revision.RollbackOf > 0 {
+				line += fmt.Sprintf("  (rollback to #%d)", revision.RollbackOf)
+			}
+			fmt.Println(line)
+		}
+	},
+}
+
+// historyShowCmd 顯示部署版本的內容
+var historyShowCmd = &cobra.Command{
+	Use:   "show <revision>",
+	Short: "Print a deployment revision",
+	Long:  `Print the services, config hashes and image digests of a revision, followed by the resolved project it deployed.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		number, err := strconv.Atoi(args[0])
+		if err != nil {
+			log.Fatalf("Invalid revision %q", args[0])
+		}
+		format, _ := cmd.Flags().GetString("format")
+
+		db := openStore(cmd)
+		defer db.Close()
+
+		project := historyProject(cmd)
+		revision := loadRevision(db, project, number)
+
+		fmt.Printf("📜 Revision %d of project %s\n", revision.Number, revision.Project)
+		fmt.Printf("  Created:  %s by %s\n", revision.CreatedAt.Format(time.DateTime), revision.User)
+		fmt.Printf("  Outcome:  %s %s\n", outcomeIcon(revision.Outcome), revision.Outcome)
+		fmt.Printf("  File:     %s\n", revision.ConfigFile)
+		fmt.Printf("  Profiles: %s\n", formatProfiles(revision.Profiles))
+		if revision.RollbackOf > 0 {
+			fmt.Printf("  Rollback: to revision %d\n", revision.RollbackOf)
+		}
+		fmt.Println("  Services:")
+		for _, s := range revision.Services {
+			status := string(s.Status)
+			if status == "" {
+				status = "not started"
+			}
+			line := fmt.Sprintf("    %s %s - Hash: %s - Image: %s", statusIcon(s.Status), s.Name, shortID(s.ConfigHash), s.Image)
+			if s.ImageDigest != "" {
+				line += "@" + s.ImageDigest
+			}
+			fmt.Println(line + " - Status: " + status)
+		}
+
+		compose, err := revisionCompose(revision)
+		if err != nil {
+			log.Fatal(err)
+		}
+		data, err := config.Marshal(compose, format)
+		if err != nil {
+			log.Fatalf("Render project failed: %v", err)
+		}
+		fmt.Println("📄 Resolved project:")
+		fmt.Print(string(data))
+	},
+}
+
+// historyEventsCmd 列出專案記錄的事件
+var historyEventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "List the recorded events of a project",
+	Long:  `List the deploy, restore, rollback and stop events recorded for the project, oldest first.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openStore(cmd)
+		defer db.Close()
+
+		project := historyProject(cmd)
+		events, err := db.GetEvents(project)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(events) == 0 {
+			fmt.Printf("🔹 Project %s has no events yet.\n", project)
+			return
+		}
+
+		fmt.Printf("📜 Events of project %s:\n", project)
+		for _, event := range events {
+			target := event.Container
+			if target == "" {
+				target = "-"
+			}
+			fmt.Printf("  %s  %-13s  %-12s  %s\n", event.CreatedAt.Format(time.DateTime), event.Type, target, event.Message)
+		}
+	},
+}
+
+// historyProject 回傳 --project 指定的專案，未指定時使用設定檔的專案名稱
+func historyProject(cmd *cobra.Command) string {
+	if name, _ := cmd.Flags().GetString("project"); name != "" {
+		return name
+	}
+	project, err := config.LoadFile(configFile(cmd))
+	if err != nil {
+		log.Fatalf("Parse Compose failed: %v", err)
+	}
+	return project.Name
+}
+
+// loadRevision 讀取專案的指定版本，不存在時結束程式
+func loadRevision(db storage.Store, project string, number int) *model.Revision {
+	revision, err := db.GetRevision(project, number)
+	if errors.Is(err, storage.ErrRevisionNotFound) {
+		log.Fatalf("Revision %d of project %s not found; see `rover history`", number, project)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	return revision
+}
+
+// revisionCompose 還原部署版本中存儲的專案
+func revisionCompose(revision *model.Revision) (config.RoverCompose, error) {
+	var project config.RoverCompose
+	if len(revision.Compose) == 0 {
+		return project, fmt.Errorf("revision %d of project %s has no stored project", revision.Number, revision.Project)
+	}
+	if err := json.Unmarshal(revision.Compose, &project); err != nil {
+		return project, fmt.Errorf("invalid project in revision %d: %v", revision.Number, err)
+	}
+	if revision.ConfigFile != "" {
+		project.WorkingDir = filepath.Dir(revision.ConfigFile)
+	}
+	return project, nil
+}
+
+// outcomeIcon 依部署結果選擇圖示
+func outcomeIcon(outcome string) string {
+	if outcome == model.OutcomeSucceeded {
+		return "✅"
+	}
+	return "❌"
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.PersistentFlags().StringP("file", "f", "", "Compose file (searched in the current and parent directories by default)")
+	historyCmd.PersistentFlags().String("project", "", "Project name (defaults to the name in the compose file)")
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyEventsCmd)
+	historyShowCmd.Flags().String("format", config.FormatYAML, "Output format of the resolved project: yaml, json or toml")
+}
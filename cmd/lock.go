@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/vvvdwbvvv/rover/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// lockCmd 解析映像檔 digest 並寫入 rover.lock
+var lockCmd = &cobra.Command{
+	Use:   "lock [service...]",
+	Short: "Pin service images to digests in rover.lock",
+	Long: `Resolve the image of every service to a digest and write rover.lock next to the compose file.
+When rover.lock exists, apply and pull use the pinned digests instead of the tags.
+Use --update to refresh only the named services (or every service) and keep the rest of the lock file,
+and --check to exit with an error when rover.lock no longer matches the compose file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filePath := configFile(cmd)
+		update, _ := cmd.Flags().GetBool("update")
+		check, _ := cmd.Flags().GetBool("check")
+
+		project, err := config.LoadFile(filePath)
+		if err != nil {
+			log.Fatalf("Parse Compose failed: %v", err)
+		}
+
+		lockPath := config.LockFilePath(project)
+		lock, exists, err := config.ReadLockFile(lockPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if check {
+			if !exists {
+				log.Fatalf("%s does not exist, run `rover lock` first", lockPath)
+			}
+			if problems := lock.Stale(project); len(problems) > 0 {
+				for _, problem := range problems {
+					fmt.Printf("❌ %s\n", problem)
+				}
+				fmt.Printf("%s is stale, run `rover lock --update`\n", lockPath)
+				os.Exit(1)
+			}
+			fmt.Printf("✅ %s is up to date\n", lockPath)
+			return
+		}
+
+		if len(args) > 0 && !update {
+			log.Fatal("Use --update to refresh specific services")
+		}
+
+		lockable := config.LockableServices(project)
+		targets := lockable
+		if update && len(args) > 0 {
+			targets = args
+		}
+		if !update {
+			lock = config.NewLockFile()
+		}
+
+		// 移除已不再需要鎖定的服務
+		keep := make(map[string]bool)
+		for _, name := range lockable {
+			keep[name] = true
+		}
+		for name := range lock.Services {
+			if !keep[name] {
+				delete(lock.Services, name)
+			}
+		}
+
+		failed := false
+		for _, name := range targets {
+			service, ok := project.Services[name]
+			if !ok {
+				log.Fatalf("No such service: %s", name)
+			}
+			if !keep[name] {
+				log.Fatalf("Service %s has no image to lock", name)
+			}
+
+			digest, err := resolveImageDigest(service.Image)
+			if err != nil {
+				log.Printf("Resolve %s for %s failed: %v", service.Image, name, err)
+				failed = true
+				continue
+			}
+			lock.Services[name] = config.LockedImage{Image: service.Image, Digest: digest}
+			fmt.Printf("🔒 %s: %s@%s\n", name, service.Image, digest)
+		}
+		if failed {
+			log.Fatalf("%s not written: some images could not be resolved", lockPath)
+		}
+
+		if err := lock.Write(lockPath); err != nil {
+			log.Fatalf("Write %s failed: %v", lockPath, err)
+		}
+		fmt.Printf("✅ %s written\n", lockPath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+	lockCmd.Flags().StringP("file", "f", "", "Compose file (searched in the current and parent directories by default)")
+	lockCmd.Flags().Bool("update", false, "Refresh only the named services (or every service) and keep the other entries")
+	lockCmd.Flags().Bool("check", false, "Exit with an error when rover.lock is missing or stale")
+}
+
+// resolveImageDigest 下載映像檔並回傳其 digest
+func resolveImageDigest(image string) (string, error) {
+	if err := pullImageWithPodman(image); err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command("podman", "image", "inspect", "--format", "{{.Digest}}", image)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%v\n%s", err, stderr.String())
+	}
+
+	digest := strings.TrimSpace(string(output))
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", fmt.Errorf("unexpected digest %q", digest)
+	}
+	return digest, nil
+}
+
+// pinImages 專案目錄有 rover.lock 時，將映像檔替換為鎖定的 digest；lock 檔過期時回傳錯誤
+func pinImages(project config.RoverCompose) (config.RoverCompose, error) {
+	pinned, lockPath, err := lockedImages(project)
+	if err == nil && lockPath != "" {
+		fmt.Printf("🔒 Using pinned images from %s\n", lockPath)
+	}
+	return pinned, err
+}
+
+// lockedImages 與 pinImages 相同但不輸出訊息；回傳使用的 lock 檔路徑，沒有 lock 檔時為空字串
+func lockedImages(project config.RoverCompose) (config.RoverCompose, string, error) {
+	lockPath := config.LockFilePath(project)
+	lock, exists, err := config.ReadLockFile(lockPath)
+	if err != nil || !exists {
+		return project, "", err
+	}
+
+	if problems := lock.Stale(project); len(problems) > 0 {
+		return project, "", fmt.Errorf("%s is stale (%s), run `rover lock --update`", lockPath, strings.Join(problems, "; "))
+	}
+	return lock.Pin(project), lockPath, nil
+}
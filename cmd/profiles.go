@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/vvvdwbvvv/rover/internal/config"
+	"github.com/vvvdwbvvv/rover/pkg/model"
+
+	"github.com/spf13/cobra"
+)
+
+// projectProfiles 回傳指令要使用的 profiles：
+// 有指定 --profile 或 COMPOSE_PROFILES 時以其為準，否則沿用專案 apply 時記錄的 profiles
+func projectProfiles(cmd *cobra.Command, project *model.ProjectState) []string {
+	profileFlags, _ := cmd.Flags().GetStringSlice("profile")
+	profiles := config.ActiveProfiles(profileFlags)
+	if len(profiles) > 0 || project == nil {
+		return profiles
+	}
+	return project.Profiles
+}
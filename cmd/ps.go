@@ -4,9 +4,14 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
-	"github.com/vvvdwbvvv/rover/pkg/storage"
+	"github.com/vvvdwbvvv/rover/internal/config"
+	"github.com/vvvdwbvvv/rover/internal/container"
+	"github.com/vvvdwbvvv/rover/pkg/model"
 
 	"github.com/spf13/cobra"
 )
@@ -14,57 +19,189 @@ import (
 // psCmd 顯示狀態
 var psCmd = &cobra.Command{
 	Use:   "ps",
-	Short: "List running containers",
-	Long:  `Show all running containers (podman ps) or only Rover-managed containers (rover ps -l)`,
+	Short: "List Rover-managed containers",
+	Long: `List the containers in the runtime that carry Rover labels, including stopped ones.
+With -l, join the stored state with the runtime and flag drift (missing, extra, stopped, wrong image).`,
 	Run: func(cmd *cobra.Command, args []string) {
 		listRoverContainers, _ := cmd.Flags().GetBool("last")
 
 		if listRoverContainers {
-			listRoverManagedContainers()
+			listRoverManagedContainers(cmd)
 		} else {
-			listAllPodmanContainers()
+			listLabeledContainers()
 		}
 	},
 }
 
-// 列出所有 Podman 容器（與 `podman ps` 一致）
-func listAllPodmanContainers() {
-	fmt.Println("📌 Listing all running Podman containers...")
-	cmd := exec.Command("podman", "ps", "--format",
-		"table {{.ID}}\t{{.Image}}\t{{.Command}}\t{{.CreatedHuman}}\t{{.Status}}\t{{.Ports}}\t{{.Names}}")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
+// 列出執行環境中帶有 Rover label 的容器，依專案與名稱排序；不讀取存儲的狀態
+func listLabeledContainers() {
+	managed, err := container.ListManaged()
+	if err != nil {
 		fmt.Println("❌ Error retrieving Podman containers:", err)
 		os.Exit(1)
 	}
+	if len(managed) == 0 {
+		fmt.Println("🔹 No Rover-managed containers found.")
+		return
+	}
+	sort.Slice(managed, func(i, j int) bool {
+		pi, pj := managed[i].Labels[container.LabelProject], managed[j].Labels[container.LabelProject]
+		if pi != pj {
+			return pi < pj
+		}
+		return managed[i].Name() < managed[j].Name()
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tSERVICE\tNAME\tID\tSTATE\tIMAGE\tREVISION")
+	for _, c := range managed {
+		revision := c.Labels[container.LabelRevision]
+		if revision == "" {
+			revision = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Labels[container.LabelProject], c.Labels[container.LabelService],
+			c.Name(), shortID(c.ID), c.State, c.Image, revision)
+	}
+	w.Flush()
 }
 
-// 列出 Rover 啟動的容器
-func listRoverManagedContainers() {
-	db, err := storage.NewBoltDB("rover.db")
+// 列出 Rover 啟動的容器，依專案分組並顯示 apply 時使用的 profiles；
+// 狀態以執行環境的實際狀態為準，並標示與存儲記錄不一致之處
+func listRoverManagedContainers(cmd *cobra.Command) {
+	db := openStore(cmd)
+	defer db.Close()
+
+	containers, err := db.GetContainers()
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer db.Close()
 
-	containers, err := db.GetContainers()
+	projects, err := db.GetProjects()
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	view, err := observeRuntime(containers)
+	runtimeKnown := err == nil
+	if !runtimeKnown {
+		warnRuntimeUnavailable(err)
+	}
+
 	fmt.Println("🚀 Rover-managed containers:")
-	if len(containers) == 0 {
+	if len(containers) == 0 && len(view.extras) == 0 && len(view.leftovers) == 0 {
 		fmt.Println("🔹 No containers were started by Rover.")
 		return
 	}
 
+	drifted := false
+	show := func(c model.ContainerState) {
+		if !runtimeKnown {
+			printContainerState(c)
+			return
+		}
+		live := view.live[c.Name]
+		drifts := detectDrift(c, live)
+		if live != nil {
+			live.Apply(&c) // 非預期的狀態變更已列在 drifts 中
+		}
+		printContainerState(c)
+		printDrifts(drifts)
+		drifted = drifted || len(drifts) > 0
+	}
+
+	known := make(map[string]bool)
+	for i := range projects {
+		project := &projects[i]
+		known[project.Name] = true
+		profiles := projectProfiles(cmd, project)
+
+		fmt.Printf("📦 Project %s (profiles: %s)\n", project.Name, formatProfiles(project.Profiles))
+		for _, c := range containers {
+			if c.Project != project.Name || !config.ProfileEnabled(c.Profiles, profiles) {
+				continue
+			}
+			show(c)
+		}
+	}
+
+	// 沒有專案記錄的舊容器
 	for _, c := range containers {
-		fmt.Printf("🟢 %s (ID: %s) - Status: %s\n", c.Name, c.ID, c.Status)
+		if !known[c.Project] {
+			show(c)
+		}
+	}
+
+	// 執行環境中有 Rover label 但沒有記錄的容器
+	for _, extra := range view.extras {
+		fmt.Printf("❓ %s (project %s, service %s) - Status: %s\n", extra.Name(), extra.Labels[container.LabelProject], extra.Labels[container.LabelService], extra.State)
+		printDrifts([]drift{{driftExtra, "container is not recorded"}})
+		drifted = true
+	}
+
+	// 中斷的 atomic 部署保留的舊容器
+	printLeftovers(view.leftovers)
+
+	if drifted {
+		fmt.Println("🔧 Run `rover state sync` to update the stored state from the runtime.")
+	}
+}
+
+func printContainerState(c model.ContainerState) {
+	line := fmt.Sprintf("%s %s (ID: %s) - Status: %s", statusIcon(c.Status), c.Name, shortID(c.ID), c.Status)
+	if !c.StatusChangedAt.IsZero() {
+		line += " since " + c.StatusChangedAt.Format(time.DateTime)
+	}
+	if c.Image != "" {
+		line += " - Image: " + c.Image
+	}
+	if len(c.Ports) > 0 {
+		ports := make([]string, 0, len(c.Ports))
+		for _, p := range c.Ports {
+			ports = append(ports, fmt.Sprintf("%d->%d/%s", p.HostPort, p.ContainerPort, p.Protocol))
+		}
+		line += " - Ports: " + strings.Join(ports, ", ")
+	}
+	if c.RestartCount > 0 {
+		line += fmt.Sprintf(" - Restarts: %d", c.RestartCount)
+	}
+	if len(c.Profiles) > 0 {
+		line += " - Profiles: " + strings.Join(c.Profiles, ", ")
+	}
+	fmt.Println(line)
+}
+
+// statusIcon 依生命週期狀態選擇圖示
+func statusIcon(status model.ContainerStatus) string {
+	switch status {
+	case model.StatusRunning, model.StatusHealthy:
+		return "🟢"
+	case model.StatusCreated, model.StatusStarting, model.StatusUnhealthy:
+		return "🟡"
+	case model.StatusExited, model.StatusRemoving, model.StatusRemoved:
+		return "⚪"
+	}
+	return "🔴"
+}
+
+// shortID 容器 ID 只顯示前 12 個字元；尚未取得 ID 時顯示 unknown
+func shortID(id string) string {
+	if id == "" {
+		return "unknown"
+	}
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func formatProfiles(profiles []string) string {
+	if len(profiles) == 0 {
+		return "default"
 	}
+	return strings.Join(profiles, ", ")
 }
 
 func init() {
-	psCmd.Flags().BoolP("last", "l", false, "Show only Rover-managed containers")
+	psCmd.Flags().BoolP("last", "l", false, "Show the stored state joined with the runtime and flag drift")
 	rootCmd.AddCommand(psCmd)
 }
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vvvdwbvvv/rover/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// pullConcurrency 同時進行的 pull 數量上限
+const pullConcurrency = 4
+
+// pullCmd 依 pull_policy 下載服務的映像檔
+var pullCmd = &cobra.Command{
+	Use:   "pull [service...]",
+	Short: "Pull images for services",
+	Long:  `Pull the images of every service (or only the named services) in parallel, honoring pull_policy. Services that are built from a build section or use pull_policy: never are skipped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filePath := configFile(cmd)
+
+		project, err := config.LoadFile(filePath)
+		if err != nil {
+			log.Fatalf("Parse Compose failed: %v", err)
+		}
+
+		project, err = pinImages(project)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		profileFlags, _ := cmd.Flags().GetStringSlice("profile")
+		project, err = project.ApplyProfiles(config.ActiveProfiles(profileFlags), args)
+		if err != nil {
+			log.Fatalf("Apply profiles failed: %v", err)
+		}
+
+		selected, err := project.WithServices(args, false)
+		if err != nil {
+			log.Fatalf("Select services failed: %v", err)
+		}
+
+		if failed := pullImages(selected); len(failed) > 0 {
+			os.Exit(1)
+		}
+		fmt.Println("✅ All images pulled successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+	pullCmd.Flags().StringP("file", "f", "", "Compose file (searched in the current and parent directories by default)")
+}
+
+// pullImages 依 pull_policy 平行下載 selected 需要的映像檔，並逐一輸出進度；
+// 回傳映像檔無法取得的服務。具有 build 區段的服務由 buildImages 處理
+func pullImages(selected config.RoverCompose) map[string]error {
+	failed := make(map[string]error)
+
+	// 多個服務共用同一映像檔時只下載一次
+	services := make(map[string][]string)
+	for _, name := range serviceNames(selected) {
+		service := selected.Services[name]
+		if service.Build != nil || service.Image == "" {
+			continue
+		}
+
+		switch service.EffectivePullPolicy() {
+		case config.PullPolicyAlways:
+		case config.PullPolicyMissing:
+			if imageExists(service.Image) {
+				continue
+			}
+		case config.PullPolicyNever:
+			if !imageExists(service.Image) {
+				err := fmt.Errorf("image %s not found locally and pull_policy is never", service.Image)
+				log.Printf("Service %s: %v", name, err)
+				failed[name] = err
+			}
+			continue
+		case config.PullPolicyBuild:
+			err := fmt.Errorf("pull_policy build requires a build section")
+			log.Printf("Service %s: %v", name, err)
+			failed[name] = err
+			continue
+		default:
+			err := fmt.Errorf("unsupported pull_policy %q", service.PullPolicy)
+			log.Printf("Service %s: %v", name, err)
+			failed[name] = err
+			continue
+		}
+		services[service.Image] = append(services[service.Image], name)
+	}
+
+	images := make([]string, 0, len(services))
+	for image := range services {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, pullConcurrency)
+	done := 0
+	for _, image := range images {
+		wg.Add(1)
+		go func(image string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			start := time.Now()
+			err := pullImageWithPodman(image)
+
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			if err != nil {
+				log.Printf("[%d/%d] Image %s pull failed: %v", done, len(images), image, err)
+				for _, name := range services[image] {
+					failed[name] = err
+				}
+				return
+			}
+			fmt.Printf("⬇️  [%d/%d] Image %s pulled (%s)\n", done, len(images), image, time.Since(start).Round(100*time.Millisecond))
+		}(image)
+	}
+	wg.Wait()
+	return failed
+}
+
+// pullImageWithPodman 以 Podman 下載映像檔
+func pullImageWithPodman(image string) error {
+	// 平行下載時進度輸出會交錯，失敗時才輸出完整紀錄
+	cmd := exec.Command("podman", "pull", "--quiet", image)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%v\n%s", err, output.String())
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/vvvdwbvvv/rover/internal/container"
+	"github.com/vvvdwbvvv/rover/pkg/model"
+)
+
+// 存儲的狀態與執行環境不一致的類型
+const (
+	driftMissing  = "missing"     // 有記錄但容器已不存在
+	driftExtra    = "extra"       // 容器帶有 Rover label 但沒有記錄
+	driftStopped  = "stopped"     // 記錄為執行中但容器已停止
+	driftImage    = "wrong image" // 容器使用的映像檔與記錄不同
+	driftStatus   = "unexpected"  // 狀態在 Rover 之外有執行環境不會自行造成的變更
+	driftLeftover = "leftover"    // 中斷的 atomic 部署保留的舊容器
+)
+
+// drift 一筆不一致
+type drift struct {
+	kind   string
+	detail string
+}
+
+// runtimeView 執行環境中與 Rover 相關的容器
+type runtimeView struct {
+	live      map[string]*container.Inspection // 有記錄的容器，key 為名稱；容器不存在時為 nil
+	extras    []container.Summary              // 帶有 Rover label 但沒有記錄的容器
+	leftovers []container.Summary              // 中斷的 atomic 部署保留的舊容器，由下次部署還原或刪除
+}
+
+// observeRuntime 以 podman inspect 讀取每個記錄的容器，並找出沒有記錄的 Rover 容器
+func observeRuntime(stored []model.ContainerState) (runtimeView, error) {
+	view := runtimeView{live: make(map[string]*container.Inspection)}
+
+	managed, err := container.ListManaged()
+	if err != nil {
+		return view, err
+	}
+
+	known := make(map[string]bool)
+	for _, c := range stored {
+		known[c.Name] = true
+		inspection, err := container.Inspect(c.Name)
+		if err != nil && !errors.Is(err, container.ErrNoSuchContainer) {
+			return view, err
+		}
+		view.live[c.Name] = inspection
+	}
+
+	for _, summary := range managed {
+		if _, ok := backupOf(summary.Name()); ok {
+			view.leftovers = append(view.leftovers, summary)
+		} else if !known[summary.Name()] {
+			view.extras = append(view.extras, summary)
+		}
+	}
+	return view, nil
+}
+
+// detectDrift 比對記錄與實際狀態
+func detectDrift(stored model.ContainerState, live *container.Inspection) []drift {
+	if live == nil {
+		return []drift{{driftMissing, "container no longer exists"}}
+	}
+
+	var drifts []drift
+	observed := stored
+	if err := live.Apply(&observed); err != nil {
+		drifts = append(drifts, drift{driftStatus, fmt.Sprintf("recorded %s, runtime %s", stored.Status, observed.Status)})
+	} else if stored.Status.Active() && !observed.Status.Active() {
+		drifts = append(drifts, drift{driftStopped, fmt.Sprintf("recorded %s, runtime %s (exit code %d)", stored.Status, observed.Status, observed.ExitCode)})
+	}
+
+	// 兩邊都有 digest 時以 digest 比較，同一映像檔的不同名稱（例如補上 registry）不算不一致
+	switch {
+	case stored.ImageDigest != "" && live.ImageDigest != "":
+		if stored.ImageDigest != live.ImageDigest {
+			drifts = append(drifts, drift{driftImage, fmt.Sprintf("recorded %s, runtime %s", stored.ImageDigest, live.ImageDigest)})
+		}
+	case stored.Image != "" && live.ImageName != "" && stored.Image != live.ImageName:
+		drifts = append(drifts, drift{driftImage, fmt.Sprintf("recorded %s, runtime %s", stored.Image, live.ImageName)})
+	}
+	return drifts
+}
+
+// printLeftovers 列出中斷的 atomic 部署保留的舊容器
+func printLeftovers(leftovers []container.Summary) {
+	for _, leftover := range leftovers {
+		project := leftover.Labels[container.LabelProject]
+		fmt.Printf("⏸️  %s (project %s, service %s) - Status: %s\n", leftover.Name(), project, leftover.Labels[container.LabelService], leftover.State)
+		printDrifts([]drift{{driftLeftover, fmt.Sprintf("kept by an interrupted atomic deployment; the next apply of project %s restores or removes it", project)}})
+	}
+}
+
+func printDrifts(drifts []drift) {
+	for _, d := range drifts {
+		fmt.Printf("    ⚠️  %s: %s\n", d.kind, d.detail)
+	}
+}
+
+// adoptContainer 以 label 與 inspect 結果建立沒有記錄的容器狀態
+func adoptContainer(name string) (model.ContainerState, error) {
+	state := model.ContainerState{Name: name}
+	inspection, err := container.Inspect(name)
+	if err != nil {
+		return state, err
+	}
+	inspection.ApplyLabels(&state)
+	return state, inspection.Apply(&state)
+}
+
+// syncProjects 依容器記錄更新專案的服務清單；沒有容器的專案刪除記錄，沒有記錄的專案新增
+func syncProjects(projects []model.ProjectState, containers []model.ContainerState, save func(model.ProjectState), remove func(string)) {
+	services := make(map[string]map[string]bool)
+	for _, c := range containers {
+		if c.Project == "" {
+			continue
+		}
+		if services[c.Project] == nil {
+			services[c.Project] = make(map[string]bool)
+		}
+		services[c.Project][c.Name] = true
+	}
+
+	for _, project := range projects {
+		names, ok := services[project.Name]
+		delete(services, project.Name)
+		if !ok {
+			remove(project.Name)
+			continue
+		}
+		project.Services = sortedKeys(names)
+		save(project)
+	}
+	for name, names := range services {
+		save(model.ProjectState{Name: name, Services: sortedKeys(names), UpdatedAt: time.Now()})
+	}
+}
+
+// warnRuntimeUnavailable 無法讀取執行環境時提示只顯示存儲的狀態
+func warnRuntimeUnavailable(err error) {
+	log.Printf("Unable to inspect runtime, showing stored state only: %v", err)
+}
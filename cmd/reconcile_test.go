@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vvvdwbvvv/rover/internal/container"
+	"github.com/vvvdwbvvv/rover/pkg/model"
+)
+
+// liveContainer 建立 podman inspect 的結果
+func liveContainer(status string, exitCode int, image, digest string) *container.Inspection {
+	live := &container.Inspection{ImageName: image, ImageDigest: digest}
+	live.State.Status = status
+	live.State.Running = status == "running"
+	live.State.ExitCode = exitCode
+	return live
+}
+
+func driftKinds(drifts []drift) []string {
+	var kinds []string
+	for _, d := range drifts {
+		kinds = append(kinds, d.kind)
+	}
+	return kinds
+}
+
+func TestDetectDrift(t *testing.T) {
+	running := model.ContainerState{Name: "web", Status: model.StatusRunning, Image: "nginx:1.25", ImageDigest: "sha256:aaa"}
+	exited := running
+	exited.Status = model.StatusExited
+	noDigest := running
+	noDigest.ImageDigest = ""
+
+	tests := []struct {
+		name   string
+		stored model.ContainerState
+		live   *container.Inspection
+		want   []string
+	}{
+		{
+			name:   "in sync",
+			stored: running,
+			live:   liveContainer("running", 0, "nginx:1.25", "sha256:aaa"),
+		},
+		{
+			name:   "missing",
+			stored: running,
+			want:   []string{driftMissing},
+		},
+		{
+			name:   "stopped",
+			stored: running,
+			live:   liveContainer("exited", 0, "nginx:1.25", "sha256:aaa"),
+			want:   []string{driftStopped},
+		},
+		{
+			name:   "crashed",
+			stored: running,
+			live:   liveContainer("exited", 137, "nginx:1.25", "sha256:aaa"),
+			want:   []string{driftStopped},
+		},
+		{
+			name:   "recorded exited and still exited",
+			stored: exited,
+			live:   liveContainer("exited", 0, "nginx:1.25", "sha256:aaa"),
+		},
+		{
+			name:   "started outside rover",
+			stored: model.ContainerState{Name: "web", Status: model.StatusRemoved},
+			live:   liveContainer("running", 0, "", ""),
+			want:   []string{driftStatus},
+		},
+		{
+			name:   "different digest",
+			stored: running,
+			live:   liveContainer("running", 0, "nginx:1.25", "sha256:bbb"),
+			want:   []string{driftImage},
+		},
+		{
+			name:   "same digest under another name",
+			stored: running,
+			live:   liveContainer("running", 0, "docker.io/library/nginx:1.25", "sha256:aaa"),
+		},
+		{
+			name:   "different name without recorded digest",
+			stored: noDigest,
+			live:   liveContainer("running", 0, "nginx:1.26", "sha256:aaa"),
+			want:   []string{driftImage},
+		},
+		{
+			name:   "same name without recorded digest",
+			stored: noDigest,
+			live:   liveContainer("running", 0, "nginx:1.25", "sha256:aaa"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := driftKinds(detectDrift(tt.stored, tt.live)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectDrift() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncProjects(t *testing.T) {
+	projects := []model.ProjectState{
+		{Name: "demo", ConfigFile: "/srv/demo/rover-compose.yaml", Services: []string{"db", "web"}},
+		{Name: "emptied", Services: []string{"worker"}},
+	}
+	containers := []model.ContainerState{
+		{Name: "web", Project: "demo"},
+		{Name: "cache", Project: "adopted"},
+		{Name: "orphan"},
+	}
+
+	saved := make(map[string]model.ProjectState)
+	var removed []string
+	syncProjects(projects, containers,
+		func(project model.ProjectState) { saved[project.Name] = project },
+		func(name string) { removed = append(removed, name) })
+
+	if !reflect.DeepEqual(removed, []string{"emptied"}) {
+		t.Errorf("removed projects = %v, want [emptied]", removed)
+	}
+	if len(saved) != 2 {
+		t.Fatalf("saved projects = %v, want demo and adopted", saved)
+	}
+	demo := saved["demo"]
+	if !reflect.DeepEqual(demo.Services, []string{"web"}) || demo.ConfigFile != projects[0].ConfigFile {
+		t.Errorf("demo saved as %+v, want services [web] and its config file kept", demo)
+	}
+	if adopted := saved["adopted"]; !reflect.DeepEqual(adopted.Services, []string{"cache"}) || adopted.UpdatedAt.IsZero() {
+		t.Errorf("adopted saved as %+v, want services [cache]", adopted)
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/vvvdwbvvv/rover/pkg/model"
+
+	"github.com/spf13/cobra"
+)
+
+// rollbackCmd 重新部署先前的版本
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback [revision]",
+	Short: "Re-apply a previous deployment revision",
+	Long: `Re-apply the resolved project stored in a revision, using its image digests. Like apply, only services whose
+configuration or image differ from the running containers, or that mount secrets or configs, are recreated.
+Without a revision, rollback returns to the last successful revision before the latest one. The rollback is
+recorded as a new revision. With --atomic, a failing service aborts the rollback and restores the containers it replaced.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openStore(cmd)
+		defer db.Close()
+
+		atomic, _ := cmd.Flags().GetBool("atomic")
+		project := historyProject(cmd)
+		var target *model.Revision
+		if len(args) == 1 {
+			number, err := strconv.Atoi(args[0])
+			if err != nil {
+				log.Fatalf("Invalid revision %q", args[0])
+			}
+			target = loadRevision(db, project, number)
+		} else {
+			revisions, err := db.GetRevisions(project)
+			if err != nil {
+				log.Fatal(err)
+			}
+			target = previousRevision(revisions)
+			if target == nil {
+				log.Fatalf("Project %s has no successful revision to roll back to; see `rover history`", project)
+			}
+		}
+
+		compose, err := revisionCompose(target)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		names := make([]string, 0, len(target.Services))
+		digests := make(map[string]string)
+		for _, s := range target.Services {
+			names = append(names, s.Name)
+			digests[s.Name] = s.ImageDigest
+		}
+		selected, err := compose.WithServices(names, false)
+		if err != nil {
+			log.Fatalf("Select services failed: %v", err)
+		}
+
+		fmt.Printf("⏪ Rolling back project %s to revision %d...\n", project, target.Number)
+		result := deploy(db, deployment{
+			project:    compose,
+			selected:   selected,
+			configFile: target.ConfigFile,
+			profiles:   target.Profiles,
+			digests:    digests,
+			atomic:     atomic,
+			rollbackOf: target.Number,
+		})
+		if !result.printSummary() {
+			log.Fatalf("Rollback to revision %d failed, recorded as revision %d", target.Number, result.revision.Number)
+		}
+		fmt.Printf("✅ Rolled back to revision %d (recorded as revision %d)\n", target.Number, result.revision.Number)
+	},
+}
+
+// previousRevision 回傳最新版本之前最近一次成功的版本
+func previousRevision(revisions []model.Revision) *model.Revision {
+	for i := len(revisions) - 2; i >= 0; i-- {
+		if revisions[i].Outcome == model.OutcomeSucceeded {
+			return &revisions[i]
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+	rollbackCmd.Flags().StringP("file", "f", "", "Compose file (searched in the current and parent directories by default)")
+	rollbackCmd.Flags().String("project", "", "Project name (defaults to the name in the compose file)")
+	rollbackCmd.Flags().Bool("atomic", false, "Restore the containers replaced by the rollback if any service fails to start")
+}
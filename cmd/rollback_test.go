@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/vvvdwbvvv/rover/pkg/model"
+)
+
+func TestPreviousRevision(t *testing.T) {
+	revision := func(number int, outcome string, rollbackOf int) model.Revision {
+		return model.Revision{Project: "demo", Number: number, Outcome: outcome, RollbackOf: rollbackOf}
+	}
+
+	tests := []struct {
+		name      string
+		revisions []model.Revision
+		want      int // 0 表示沒有可回復的版本
+	}{
+		{
+			name: "no revisions",
+		},
+		{
+			name:      "only the latest revision",
+			revisions: []model.Revision{revision(1, model.OutcomeSucceeded, 0)},
+		},
+		{
+			name: "only failed revisions",
+			revisions: []model.Revision{
+				revision(1, model.OutcomeFailed, 0),
+				revision(2, model.OutcomeRolledBack, 0),
+				revision(3, model.OutcomeFailed, 0),
+			},
+		},
+		{
+			name: "skips failed and rolled back revisions",
+			revisions: []model.Revision{
+				revision(1, model.OutcomeSucceeded, 0),
+				revision(2, model.OutcomeSucceeded, 0),
+				revision(3, model.OutcomeFailed, 0),
+				revision(4, model.OutcomeRolledBack, 0),
+				revision(5, model.OutcomeFailed, 0),
+			},
+			want: 2,
+		},
+		{
+			name: "latest revision failed",
+			revisions: []model.Revision{
+				revision(1, model.OutcomeSucceeded, 0),
+				revision(2, model.OutcomeFailed, 0),
+			},
+			want: 1,
+		},
+		{
+			name: "latest revision is a rollback",
+			revisions: []model.Revision{
+				revision(1, model.OutcomeSucceeded, 0),
+				revision(2, model.OutcomeSucceeded, 0),
+				revision(3, model.OutcomeSucceeded, 1),
+			},
+			want: 2,
+		},
+		{
+			name: "earlier rollback is a valid target",
+			revisions: []model.Revision{
+				revision(1, model.OutcomeSucceeded, 0),
+				revision(2, model.OutcomeFailed, 0),
+				revision(3, model.OutcomeSucceeded, 1),
+				revision(4, model.OutcomeSucceeded, 0),
+			},
+			want: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := previousRevision(tt.revisions)
+			switch {
+			case tt.want == 0 && got != nil:
+				t.Errorf("previousRevision() = %d, want none", got.Number)
+			case tt.want != 0 && (got == nil || got.Number != tt.want):
+				t.Errorf("previousRevision() = %v, want %d", got, tt.want)
+			}
+		})
+	}
+}
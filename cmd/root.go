@@ -2,6 +2,9 @@ package cmd
 
 import (
 	"fmt"
+
+	"github.com/vvvdwbvvv/rover/pkg/storage"
+
 	"github.com/spf13/cobra"
 )
 
@@ -15,3 +18,10 @@ func Execute() {
 		fmt.Println(err)
 	}
 }
+
+func init() {
+	rootCmd.PersistentFlags().StringSlice("profile", nil, "Specify a profile to enable (defaults to COMPOSE_PROFILES)")
+	rootCmd.PersistentFlags().String("storage", storage.BackendFromEnv(), "State storage backend: bolt, memory or json (defaults to ROVER_STORAGE)")
+	rootCmd.PersistentFlags().String("state-dir", "", "State directory (defaults to ROVER_STATE_DIR or $XDG_STATE_HOME/rover)")
+	rootCmd.PersistentFlags().Duration("lock-timeout", 0, "How long to wait when another rover process holds the state lock")
+}
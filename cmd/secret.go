@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/vvvdwbvvv/rover/internal/config"
+	"github.com/vvvdwbvvv/rover/internal/secrets"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// secretCmd 管理 encrypted_file provider 中的 secret
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage secrets in the local encrypted store",
+	Long: `Manage the secrets of an encrypted_file provider declared in secret_providers.
+The store is encrypted with a passphrase (ROVER_SECRET_PASSPHRASE, passphrase_env or a prompt) or a key file, so plaintext never reaches the project directory.
+In docker-compose files, declare providers under x-rover-secret-providers and mark provider-backed secrets
+as external: true with x-rover-provider (and optionally x-rover-key).`,
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Set a secret, reading the value from stdin",
+	Long: `Set a secret, reading the value from stdin. The first set creates the encrypted store;
+a passphrase typed at the prompt must then be entered twice.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store := openSecretStore(cmd, true)
+
+		value, err := readSecretValue(args[0])
+		if err != nil {
+			log.Fatalf("Read secret value failed: %v", err)
+		}
+		store.Set(args[0], value)
+		if err := store.Save(); err != nil {
+			log.Fatalf("Save secret store failed: %v", err)
+		}
+		fmt.Printf("🔑 Secret %s saved to %s\n", args[0], store.Path())
+	},
+}
+
+var secretRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove a secret",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store := openSecretStore(cmd, false)
+		if !store.Delete(args[0]) {
+			log.Fatalf("No such secret: %s", args[0])
+		}
+		if err := store.Save(); err != nil {
+			log.Fatalf("Save secret store failed: %v", err)
+		}
+		fmt.Printf("🗑️  Secret %s removed from %s\n", args[0], store.Path())
+	},
+}
+
+var secretLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List secret names (values are never printed)",
+	Run: func(cmd *cobra.Command, args []string) {
+		store := openSecretStore(cmd, false)
+		for _, key := range store.Keys() {
+			fmt.Println(key)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+	for _, c := range []*cobra.Command{secretSetCmd, secretRmCmd, secretLsCmd} {
+		c.Flags().StringP("file", "f", "", "Compose file (searched in the current and parent directories by default)")
+		c.Flags().String("provider", "", "encrypted_file provider to use (required when several are declared)")
+		secretCmd.AddCommand(c)
+	}
+}
+
+// openSecretStore 開啟 --provider 指定的加密檔；未指定時使用唯一的 encrypted_file provider。
+// create 為 true 時可能建立新的加密檔，提示輸入的密語需確認
+func openSecretStore(cmd *cobra.Command, create bool) *secrets.Store {
+	project, err := config.LoadFile(configFile(cmd))
+	if err != nil {
+		log.Fatalf("Parse Compose failed: %v", err)
+	}
+
+	name, _ := cmd.Flags().GetString("provider")
+	if name == "" {
+		var candidates []string
+		for n, provider := range project.SecretProviders {
+			if provider.Type == config.SecretProviderEncryptedFile {
+				candidates = append(candidates, n)
+			}
+		}
+		sort.Strings(candidates)
+		switch len(candidates) {
+		case 0:
+			log.Fatalf("No %s provider declared in secret_providers", config.SecretProviderEncryptedFile)
+		case 1:
+			name = candidates[0]
+		default:
+			log.Fatalf("Several %s providers declared (%v), use --provider to choose one", config.SecretProviderEncryptedFile, candidates)
+		}
+	}
+
+	store, err := project.OpenSecretStore(name, create)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return store
+}
+
+// readSecretValue 從終端機讀取時不回顯輸入；否則讀取整個 stdin
+func readSecretValue(name string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+		value, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return value, err
+	}
+
+	value, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(value, []byte("\n")), nil
+}
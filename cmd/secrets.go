@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/vvvdwbvvv/rover/internal/config"
+)
+
+// secretMounts 記錄本次 apply 已讀取的內容與已建立的 Podman secret，同一個 secret 只讀取與建立一次
+type secretMounts struct {
+	project  config.RoverCompose
+	resolver *config.SecretResolver
+	values   map[string][]byte // key 為 Podman secret 名稱
+	created  map[string]bool
+}
+
+func newSecretMounts(project config.RoverCompose) *secretMounts {
+	return &secretMounts{
+		project:  project,
+		resolver: config.NewSecretResolver(project),
+		values:   make(map[string][]byte),
+		created:  make(map[string]bool),
+	}
+}
+
+// mount 服務掛載的一個 secret 或 config
+type mount struct {
+	kind string // secret 或 config
+	ref  config.FileReference
+}
+
+// name 回傳對應的 Podman secret 名稱
+func (m mount) name(project string) string {
+	return podmanSecretName(project, m.kind, m.ref.Source)
+}
+
+// serviceMounts 依宣告順序回傳服務的 secrets 與 configs
+func serviceMounts(service config.Service) []mount {
+	var mounts []mount
+	for _, ref := range service.Secrets {
+		mounts = append(mounts, mount{"secret", ref})
+	}
+	for _, ref := range service.Configs {
+		mounts = append(mounts, mount{"config", ref})
+	}
+	return mounts
+}
+
+// value 讀取 secret 或 config 的內容，同一個來源只讀取一次
+func (s *secretMounts) value(m mount) ([]byte, error) {
+	name := m.name(s.project.Name)
+	if value, ok := s.values[name]; ok {
+		return value, nil
+	}
+	var value []byte
+	var err error
+	if m.kind == "secret" {
+		value, err = s.resolver.Secret(m.ref.Source)
+	} else {
+		value, err = s.resolver.Config(m.ref.Source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.values[name] = value
+	return value, nil
+}
+
+// contentDigest 回傳服務掛載的 secrets / configs 內容的雜湊，沒有掛載時回傳空字串。
+// 只記錄內容的 sha256，不保存明文；內容變更時設定雜湊隨之改變，服務才會重新建立
+func (s *secretMounts) contentDigest(service config.Service) (string, error) {
+	mounts := serviceMounts(service)
+	if len(mounts) == 0 {
+		return "", nil
+	}
+	digest := sha256.New()
+	for _, m := range mounts {
+		value, err := s.value(m)
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(value)
+		fmt.Fprintf(digest, "%s %s %s\n", m.kind, m.ref.Source, hex.EncodeToString(sum[:]))
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// Names 回傳已建立的 Podman secret 名稱
+func (s *secretMounts) Names() []string {
+	return sortedKeys(s.created)
+}
+
+// mountArgs 建立服務需要的 secrets / configs，並回傳 podman run 的 --secret 參數。
+// 內容只透過 stdin 傳給 Podman，不會出現在參數、輸出或 BoltDB 中
+func (s *secretMounts) mountArgs(service config.Service) ([]string, error) {
+	var args []string
+	for _, m := range serviceMounts(service) {
+		name := m.name(s.project.Name)
+		if !s.created[name] {
+			value, err := s.value(m)
+			if err != nil {
+				return nil, err
+			}
+			if err := createPodmanSecret(name, value); err != nil {
+				return nil, fmt.Errorf("%s %s: %v", m.kind, m.ref.Source, err)
+			}
+			s.created[name] = true
+		}
+		target := m.ref.ConfigMountPath()
+		if m.kind == "secret" {
+			target = m.ref.SecretMountPath()
+		}
+		args = append(args, "--secret", secretMountOption(name, target, m.ref))
+	}
+	return args, nil
+}
+
+// podmanSecretName 以專案名稱區分不同專案的 secret；secrets 與 configs 分開命名避免衝突
+func podmanSecretName(project, kind, name string) string {
+	return fmt.Sprintf("%s_%s_%s", project, kind, name)
+}
+
+// secretMountOption 組合 --secret 的掛載選項
+func secretMountOption(name, target string, ref config.FileReference) string {
+	options := []string{"source=" + name, "type=mount", "target=" + target}
+	if ref.UID != "" {
+		options = append(options, "uid="+ref.UID)
+	}
+	if ref.GID != "" {
+		options = append(options, "gid="+ref.GID)
+	}
+	if ref.Mode != nil {
+		options = append(options, fmt.Sprintf("mode=%04o", *ref.Mode))
+	}
+	return strings.Join(options, ",")
+}
+
+// createPodmanSecret 建立或取代 Podman secret，內容經由 stdin 傳入
+func createPodmanSecret(name string, value []byte) error {
+	cmd := exec.Command("podman", "secret", "create", "--replace", name, "-")
+	cmd.Stdin = bytes.NewReader(value)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// removePodmanSecrets 刪除專案建立的 Podman secret
+func removePodmanSecrets(names []string) {
+	for _, name := range names {
+		if err := exec.Command("podman", "secret", "rm", name).Run(); err != nil {
+			fmt.Printf("❌ Unable to remove secret %s: %v\n", name, err)
+			continue
+		}
+		fmt.Printf("🔑 Secret %s removed\n", name)
+	}
+}
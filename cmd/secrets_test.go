@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vvvdwbvvv/rover/internal/config"
+)
+
+// TestServiceConfigHashSecretContents secrets / configs 的內容變更時設定雜湊才改變，且雜湊中不含明文
+func TestServiceConfigHashSecretContents(t *testing.T) {
+	web := config.Service{Name: "web", Image: "nginx", Configs: []config.FileReference{{Source: "site"}}}
+	project := func(content string) config.RoverCompose {
+		return config.RoverCompose{
+			Name:     "demo",
+			Services: map[string]config.Service{"web": web},
+			Configs:  map[string]config.FileObject{"site": {Content: content}},
+		}
+	}
+	hash := func(project config.RoverCompose, service config.Service) string {
+		t.Helper()
+		digest, err := newSecretMounts(project).contentDigest(service)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(digest, "listen 80") {
+			t.Fatalf("content digest %q contains the plaintext", digest)
+		}
+		hash, err := serviceConfigHash(project, service, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+
+	original := hash(project("listen 80;"), web)
+	if again := hash(project("listen 80;"), web); again != original {
+		t.Errorf("hash changed without a content change: %s != %s", again, original)
+	}
+	if changed := hash(project("listen 8080;"), web); changed == original {
+		t.Error("hash did not change when the config content changed")
+	}
+
+	// 沒有掛載 secrets / configs 的服務不受影響
+	plain := config.Service{Name: "web", Image: "nginx"}
+	if digest, err := newSecretMounts(project("listen 80;")).contentDigest(plain); err != nil || digest != "" {
+		t.Errorf("contentDigest() without mounts = %q, %v, want empty", digest, err)
+	}
+
+	if _, err := newSecretMounts(project("listen 80;")).contentDigest(config.Service{Configs: []config.FileReference{{Source: "missing"}}}); err == nil {
+		t.Error("contentDigest() with an undefined config succeeded")
+	}
+}
@@ -0,0 +1,405 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vvvdwbvvv/rover/internal/container"
+	"github.com/vvvdwbvvv/rover/pkg/model"
+	"github.com/vvvdwbvvv/rover/pkg/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// stateCmd 管理 Rover 的狀態儲存
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Manage Rover's state storage",
+}
+
+// stateMigrateCmd 升級 BoltDB 的 schema
+var stateMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the state database schema",
+	Long:  `Run pending schema migrations on the BoltDB state database. The database is backed up before migrating. Migrations also run automatically whenever rover opens the database.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if backend, _ := cmd.Flags().GetString("storage"); backend != storage.BackendBolt {
+			log.Fatalf("Only the %s backend has a schema to migrate (current backend: %s)", storage.BackendBolt, backend)
+		}
+
+		config := storageConfig(cmd)
+		dir, dbPath, err := config.ResolvePath()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+			fmt.Printf("🔹 %s does not exist; it will be created with schema version %d.\n", dbPath, storage.SchemaVersion())
+			return
+		}
+
+		// 持有鎖定，避免其他 rover 程序在檢查與升級之間開啟資料庫
+		lock, err := storage.LockStateDir(dir, config.LockTimeout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer lock.Release()
+
+		version, pending, err := storage.InspectBoltDB(dbPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("📦 %s: schema version %d (latest %d)\n", dbPath, version, storage.SchemaVersion())
+		if version > storage.SchemaVersion() {
+			log.Fatalf("Database schema version %d is newer than this rover supports", version)
+		}
+		if len(pending) == 0 {
+			fmt.Println("✅ Schema is up to date")
+			return
+		}
+
+		for _, m := range pending {
+			fmt.Printf("  → %d: %s\n", m.Version, m.Description)
+		}
+		if dryRun {
+			fmt.Printf("🔹 Dry run: %d migration(s) pending, nothing changed\n", len(pending))
+			return
+		}
+
+		db, err := storage.NewBoltDB(dbPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer db.Close()
+
+		// 空的資料庫直接建立 schema，不需要備份
+		if _, backup := db.Migrated(); backup != "" {
+			fmt.Printf("💾 Backup written to %s\n", backup)
+		}
+		fmt.Printf("✅ Migrated to schema version %d\n", storage.SchemaVersion())
+	},
+}
+
+// stateSyncCmd 以執行環境的實際狀態修正存儲的狀態
+var stateSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Repair the stored state from the container runtime",
+	Long: `Inspect every recorded container and update its stored state, drop records of containers that no longer exist,
+and adopt containers that carry Rover labels but are not recorded.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		db := openStore(cmd)
+		defer db.Close()
+
+		containers, err := db.GetContainers()
+		if err != nil {
+			log.Fatal(err)
+		}
+		projects, err := db.GetProjects()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		view, err := observeRuntime(containers)
+		if err != nil {
+			log.Fatalf("Unable to inspect runtime: %v", err)
+		}
+
+		var synced []model.ContainerState
+		changes := 0
+		for _, c := range containers {
+			live := view.live[c.Name]
+			drifts := detectDrift(c, live)
+			if live == nil {
+				fmt.Printf("🗑️  %s: container no longer exists, removing record\n", c.Name)
+				changes++
+				if !dryRun {
+					db.DeleteContainer(c.Name)
+				}
+				continue
+			}
+
+			if len(drifts) > 0 {
+				fmt.Printf("🔄 %s: updating record\n", c.Name)
+				printDrifts(drifts)
+				changes++
+			}
+			live.Apply(&c) // 非預期的狀態變更已列在 drifts 中，仍以執行環境為準並在歷程中標記
+			synced = append(synced, c)
+			if !dryRun {
+				if err := db.SaveContainer(c); err != nil {
+					log.Printf("Unable to save container %s: %v", c.Name, err)
+				}
+			}
+		}
+
+		// 中斷的 atomic 部署保留的舊容器不納入記錄，由下次部署處理
+		printLeftovers(view.leftovers)
+
+		for _, extra := range view.extras {
+			fmt.Printf("➕ %s: adopting container of project %s\n", extra.Name(), extra.Labels[container.LabelProject])
+			changes++
+			state, err := adoptContainer(extra.Name())
+			if err != nil {
+				log.Printf("Unable to adopt container %s: %v", extra.Name(), err)
+				continue
+			}
+			synced = append(synced, state)
+			if !dryRun {
+				if err := db.SaveContainer(state); err != nil {
+					log.Printf("Unable to save container %s: %v", state.Name, err)
+				}
+			}
+		}
+
+		if dryRun {
+			fmt.Printf("🔹 Dry run: %d change(s) found, nothing changed\n", changes)
+			return
+		}
+		syncProjects(projects, synced,
+			func(project model.ProjectState) { db.SaveProject(project) },
+			func(name string) { db.DeleteProject(name) })
+
+		if changes == 0 {
+			fmt.Println("✅ Stored state already matches the runtime")
+			return
+		}
+		fmt.Printf("✅ Stored state synced (%d change(s))\n", changes)
+	},
+}
+
+// stateRebuildCmd 以容器上的 label 重建存儲的狀態
+var stateRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Reconstruct the stored state from container labels",
+	Long: `Rebuild container and project records from the labels rover puts on every container it starts
+(project, service, config hash, revision, profiles and compose file). Use it when the state database was lost or
+corrupted. Records of containers that no longer exist are dropped; status history of existing records is kept.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		db := openStore(cmd)
+		defer db.Close()
+
+		managed, err := container.ListManaged()
+		if err != nil {
+			log.Fatalf("Unable to inspect runtime: %v", err)
+		}
+
+		stored, err := db.GetContainers()
+		if err != nil {
+			log.Fatal(err)
+		}
+		previous := make(map[string]model.ContainerState)
+		for _, c := range stored {
+			previous[c.Name] = c
+		}
+
+		projects := make(map[string]*model.ProjectState)
+		var rebuilt []model.ContainerState
+		for _, summary := range managed {
+			name := summary.Name()
+			if _, ok := backupOf(name); ok {
+				fmt.Printf("⏸️  %s: kept by an interrupted atomic deployment, skipped\n", name)
+				continue
+			}
+			inspection, err := container.Inspect(name)
+			if err != nil {
+				log.Printf("Unable to inspect container %s: %v", name, err)
+				continue
+			}
+
+			state, ok := previous[name]
+			if !ok {
+				state = model.ContainerState{Name: name}
+			}
+			delete(previous, name)
+			inspection.ApplyLabels(&state)
+			if err := inspection.Apply(&state); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			rebuilt = append(rebuilt, state)
+
+			project := projects[state.Project]
+			if project == nil {
+				project = rebuiltProject(db, state.Project)
+				projects[state.Project] = project
+			}
+			if file := inspection.ConfigFile(); file != "" {
+				project.ConfigFile = file
+			}
+			project.Services = append(project.Services, state.Name)
+			project.Profiles = append(project.Profiles, state.Profiles...)
+
+			fmt.Printf("🔄 %s: project %s, service %s, revision %d\n", name, state.Project, state.Service, state.Revision)
+		}
+		for name := range previous {
+			fmt.Printf("🗑️  %s: container no longer exists, removing record\n", name)
+		}
+
+		if dryRun {
+			fmt.Printf("🔹 Dry run: %d container(s) and %d project(s) would be rebuilt, nothing changed\n", len(rebuilt), len(projects))
+			return
+		}
+
+		for _, state := range rebuilt {
+			if err := db.SaveContainer(state); err != nil {
+				log.Printf("Unable to save container %s: %v", state.Name, err)
+			}
+		}
+		for name := range previous {
+			if err := db.DeleteContainer(name); err != nil {
+				log.Printf("Unable to delete container %s: %v", name, err)
+			}
+		}
+
+		storedProjects, err := db.GetProjects()
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, project := range storedProjects {
+			if projects[project.Name] == nil {
+				db.DeleteProject(project.Name)
+			}
+		}
+		for _, project := range projects {
+			project.Services = uniqueSorted(project.Services)
+			project.Profiles = uniqueSorted(project.Profiles)
+			if err := db.SaveProject(*project); err != nil {
+				log.Printf("Unable to save project %s: %v", project.Name, err)
+			}
+		}
+
+		fmt.Printf("✅ Rebuilt %d container(s) in %d project(s) from labels\n", len(rebuilt), len(projects))
+	},
+}
+
+// stateExportCmd 將狀態匯出為 JSON
+var stateExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the stored state as JSON",
+	Long:  `Write every project, container, revision and event as versioned JSON, to stdout or to the file given with --output. The export does not depend on the storage backend.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		db := openStore(cmd)
+		defer db.Close()
+
+		snapshot, err := storage.Export(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if output == "" || output == "-" {
+			if err := snapshot.Write(os.Stdout); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		file, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := snapshot.Write(file); err != nil {
+			file.Close()
+			log.Fatal(err)
+		}
+		if err := file.Close(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("✅ Exported %d project(s), %d container(s), %d revision(s) and %d event(s) to %s\n",
+			len(snapshot.Projects), len(snapshot.Containers), len(snapshot.Revisions), len(snapshot.Events), output)
+	},
+}
+
+// stateImportCmd 匯入 state export 產生的 JSON
+var stateImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import state exported with `rover state export`",
+	Long: `Validate an export and load it into the state storage, typically a fresh one selected with --state-dir or --storage.
+Records identical to existing ones are skipped. Records that differ are conflicts: by default nothing is imported
+(--on-conflict fail); use skip to keep the existing records, or overwrite to replace existing projects and containers.
+Revisions are immutable and are never overwritten. All records are written at once: if writing fails, nothing is imported.
+Use - to read from stdin.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		policy, _ := cmd.Flags().GetString("on-conflict")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		input := os.Stdin
+		if args[0] != "-" {
+			file, err := os.Open(args[0])
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer file.Close()
+			input = file
+		}
+		snapshot, err := storage.ReadSnapshot(input)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		db := openStore(cmd)
+		defer db.Close()
+
+		result, err := storage.Import(db, snapshot, policy, dryRun)
+		if err != nil {
+			db.Close()
+			log.Fatalf("Import aborted: %v", err)
+		}
+		for _, conflict := range result.Conflicts {
+			if policy == storage.ConflictOverwrite {
+				fmt.Printf("⚠️  %s differs, overwriting the existing record\n", conflict)
+			} else {
+				fmt.Printf("⚠️  %s differs, keeping the existing record\n", conflict)
+			}
+		}
+
+		if dryRun {
+			fmt.Printf("🔹 Dry run: %d record(s) would be imported, %d already present, nothing changed\n", result.Imported, result.Unchanged)
+			return
+		}
+		fmt.Printf("✅ Imported %d record(s), %d already present\n", result.Imported, result.Unchanged)
+	},
+}
+
+// rebuiltProject 回傳重建用的專案記錄；沿用既有記錄中無法從 label 得知的 secrets 與 profiles
+func rebuiltProject(db storage.Store, name string) *model.ProjectState {
+	project := &model.ProjectState{Name: name, UpdatedAt: time.Now()}
+	if previous, err := db.GetProject(name); err == nil {
+		project.ConfigFile = previous.ConfigFile
+		project.Profiles = previous.Profiles
+		project.Secrets = previous.Secrets
+	}
+	return project
+}
+
+func uniqueSorted(values []string) []string {
+	set := make(map[string]bool)
+	for _, v := range values {
+		set[v] = true
+	}
+	return sortedKeys(set)
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateMigrateCmd)
+	stateMigrateCmd.Flags().Bool("dry-run", false, "Show pending migrations without applying them")
+	stateCmd.AddCommand(stateSyncCmd)
+	stateSyncCmd.Flags().Bool("dry-run", false, "Show what would change without updating the stored state")
+	stateCmd.AddCommand(stateRebuildCmd)
+	stateRebuildCmd.Flags().Bool("dry-run", false, "Show what would be rebuilt without updating the stored state")
+	stateCmd.AddCommand(stateExportCmd)
+	stateExportCmd.Flags().StringP("output", "o", "", "Write the export to a file instead of stdout")
+	stateCmd.AddCommand(stateImportCmd)
+	stateImportCmd.Flags().String("on-conflict", storage.ConflictFail, "How to handle records that differ from existing ones: "+strings.Join(storage.ConflictPolicies, ", "))
+	stateImportCmd.Flags().Bool("dry-run", false, "Validate the export and report conflicts without importing")
+}
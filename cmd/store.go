@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/vvvdwbvvv/rover/pkg/model"
+	"github.com/vvvdwbvvv/rover/pkg/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// storageConfig 依 --storage、--state-dir 與 --lock-timeout 組合儲存設定
+func storageConfig(cmd *cobra.Command) storage.Config {
+	backend, _ := cmd.Flags().GetString("storage")
+	dir, _ := cmd.Flags().GetString("state-dir")
+	timeout, _ := cmd.Flags().GetDuration("lock-timeout")
+	return storage.Config{Backend: backend, Dir: dir, LockTimeout: timeout}
+}
+
+// openStore 開啟狀態儲存；其他 rover 程序持有鎖定時依 --lock-timeout 等待
+func openStore(cmd *cobra.Command) storage.Store {
+	config := storageConfig(cmd)
+	warnLegacyState(config)
+
+	store, err := storage.Open(config)
+	if err != nil {
+		if _, ok := err.(*storage.LockError); ok {
+			log.Fatalf("%v; retry later or use --lock-timeout to wait", err)
+		}
+		log.Fatal(err)
+	}
+	return store
+}
+
+// warnLegacyState 目前目錄仍有舊版的 rover.db 而狀態目錄沒有時，提示搬移
+func warnLegacyState(config storage.Config) {
+	if config.Backend != storage.BackendBolt {
+		return
+	}
+	_, path, err := config.ResolvePath()
+	if err != nil {
+		return
+	}
+	legacy, err := filepath.Abs(storage.DefaultPath(storage.BackendBolt))
+	if err != nil || legacy == path {
+		return
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		fmt.Printf("⚠️  Found %s from an older rover; move it to %s to keep its state\n", legacy, path)
+	}
+}
+
+// recordContainer 以 podman inspect 補上執行環境的實際狀態後存儲並回傳；無法 inspect 時只存儲 Rover 已知的欄位
+func recordContainer(db storage.Store, state model.ContainerState) model.ContainerState {
+	return recordInspected(db, podmanCLI{}, state)
+}
+
+// recordInspected 與 recordContainer 相同，但透過 runner inspect 容器
+func recordInspected(db storage.Store, runner podmanRunner, state model.ContainerState) model.ContainerState {
+	if inspection, err := runner.Inspect(state.Name); err != nil {
+		log.Printf("Unable to inspect container %s: %v", state.Name, err)
+	} else if err := inspection.Apply(&state); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if err := db.SaveContainer(state); err != nil {
+		log.Printf("Unable to save container %s: %v", state.Name, err)
+	}
+	return state
+}
+
+// refreshContainer 重新 inspect 已記錄的容器並更新存儲
+func refreshContainer(db storage.Store, name string) {
+	state, err := db.GetContainer(name)
+	if err != nil {
+		return
+	}
+	recordContainer(db, *state)
+}
+
+// newRevision 為本次部署保留下一個版本號；部署結束後才以 saveRevision 存儲。
+// 狀態目錄的鎖定確保部署期間沒有其他 rover 程序使用相同的版本號
+func newRevision(db storage.Store, project string) *model.Revision {
+	revision := &model.Revision{
+		Project:   project,
+		Number:    1,
+		User:      currentUser(),
+		CreatedAt: time.Now(),
+	}
+	revisions, err := db.GetRevisions(project)
+	if err != nil {
+		log.Printf("Unable to read revisions of project %s: %v", project, err)
+	}
+	if n := len(revisions); n > 0 {
+		revision.Number = revisions[n-1].Number + 1
+	}
+	return revision
+}
+
+// saveRevision 存儲部署版本
+func saveRevision(db storage.Store, revision *model.Revision) {
+	if err := db.SaveRevision(revision); err != nil {
+		log.Printf("Unable to record revision %d of project %s: %v", revision.Number, revision.Project, err)
+	}
+}
+
+// recordEvent 存儲專案或容器的事件；無法存儲時只記錄警告
+func recordEvent(db storage.Store, project, container, eventType, message string) {
+	event := model.Event{
+		Project:   project,
+		Container: container,
+		Type:      eventType,
+		Message:   message,
+		CreatedAt: time.Now(),
+	}
+	if err := db.AddEvent(event); err != nil {
+		log.Printf("Unable to record %s event of %s: %v", eventType, project, err)
+	}
+}
+
+// currentUser 回傳執行 rover 的使用者名稱
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// transitionContainer 變更容器狀態並存儲；不合法的轉換只記錄警告
+func transitionContainer(db storage.Store, state *model.ContainerState, to model.ContainerStatus) {
+	if err := state.Transition(to, time.Now()); err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	if err := db.SaveContainer(*state); err != nil {
+		log.Printf("Unable to save container %s: %v", state.Name, err)
+	}
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/vvvdwbvvv/rover/internal/container"
+	"github.com/vvvdwbvvv/rover/pkg/model"
+	"github.com/vvvdwbvvv/rover/pkg/storage"
+)
+
+// backupSuffix atomic 部署期間保留舊容器時使用的名稱後綴
+const backupSuffix = "-rover-previous"
+
+// podmanRunner atomic 部署使用的容器操作；podmanCLI 執行 podman，測試時以假的實作取代
+type podmanRunner interface {
+	Inspect(name string) (*container.Inspection, error)
+	ListManaged() ([]container.Summary, error)
+	// Run 執行 podman 子指令，例如 stop、rename、start、rm
+	Run(args ...string) error
+}
+
+// podmanCLI 以 podman 指令操作容器
+type podmanCLI struct{}
+
+func (podmanCLI) Inspect(name string) (*container.Inspection, error) {
+	return container.Inspect(name)
+}
+
+func (podmanCLI) ListManaged() ([]container.Summary, error) {
+	return container.ListManaged()
+}
+
+func (podmanCLI) Run(args ...string) error {
+	return podman(args...)
+}
+
+// replacedContainer 被新容器取代的舊容器
+type replacedContainer struct {
+	exists  bool                  // 部署前是否有同名容器
+	running bool                  // 部署前是否執行中，還原時依此決定是否啟動
+	record  *model.ContainerState // 部署前的記錄，沒有記錄時為 nil
+}
+
+// transaction 記錄 atomic 部署中重新建立的服務；舊容器先停止並改名保留，
+// 部署失敗時刪除新容器並還原舊容器，成功時才刪除舊容器
+type transaction struct {
+	db       storage.Store
+	runner   podmanRunner
+	project  string
+	order    []string // 依啟動順序
+	replaced map[string]replacedContainer
+}
+
+func newTransaction(db storage.Store, runner podmanRunner, project string) *transaction {
+	return &transaction{db: db, runner: runner, project: project, replaced: make(map[string]replacedContainer)}
+}
+
+// replace 在建立 name 的新容器前停止舊容器並改名保留
+func (t *transaction) replace(name string) error {
+	previous := replacedContainer{}
+	inspection, err := t.runner.Inspect(name)
+	switch {
+	case errors.Is(err, container.ErrNoSuchContainer):
+	case err != nil:
+		return err
+	default:
+		previous.exists = true
+		previous.running = inspection.State.Running
+		if record, err := t.db.GetContainer(name); err == nil {
+			previous.record = record
+		}
+		// 部署中斷時 recoverLeftovers 依此事件決定還原後是否啟動舊容器
+		recordEvent(t.db, t.project, name, model.EventSetAside, inspection.State.Status)
+		if previous.running {
+			if err := t.runner.Run("stop", name); err != nil {
+				return fmt.Errorf("stop previous container: %w", err)
+			}
+		}
+		if err := t.runner.Run("rename", name, name+backupSuffix); err != nil {
+			return fmt.Errorf("set aside previous container: %w", err)
+		}
+	}
+
+	t.order = append(t.order, name)
+	t.replaced[name] = previous
+	return nil
+}
+
+// rollback 依反向順序刪除新容器，再依啟動順序還原舊容器；回傳還原的服務
+func (t *transaction) rollback() []string {
+	for i := len(t.order) - 1; i >= 0; i-- {
+		name := t.order[i]
+		fmt.Printf("↩️  Removing new container %s...\n", name)
+		t.runner.Run("rm", "-f", name)
+	}
+
+	var restored []string
+	for _, name := range t.order {
+		previous := t.replaced[name]
+		if !previous.exists {
+			t.db.DeleteContainer(name)
+			continue
+		}
+
+		fmt.Printf("↩️  Restoring previous container %s...\n", name)
+		if err := t.runner.Run("rename", name+backupSuffix, name); err != nil {
+			log.Printf("Unable to restore container %s: %v", name, err)
+			continue
+		}
+		if previous.running {
+			if err := t.runner.Run("start", name); err != nil {
+				log.Printf("Unable to start restored container %s: %v", name, err)
+			}
+		}
+		if previous.record != nil {
+			recordInspected(t.db, t.runner, *previous.record)
+		} else {
+			t.db.DeleteContainer(name)
+		}
+		recordEvent(t.db, t.project, name, model.EventRestored, "atomic deployment failed")
+		restored = append(restored, name)
+	}
+	return restored
+}
+
+// commit 部署成功後刪除保留的舊容器
+func (t *transaction) commit() {
+	for _, name := range t.order {
+		if !t.replaced[name].exists {
+			continue
+		}
+		if err := t.runner.Run("rm", name+backupSuffix); err != nil {
+			log.Printf("Unable to remove previous container %s: %v", name+backupSuffix, err)
+		}
+	}
+}
+
+// backupOf 名稱為保留的舊容器時回傳原本的容器名稱
+func backupOf(name string) (string, bool) {
+	return strings.CutSuffix(name, backupSuffix)
+}
+
+// recoverLeftovers 處理先前中斷的 atomic 部署留下的舊容器：同名的新容器已存在時刪除舊容器，
+// 不存在時將舊容器改回原名，保留前執行中的才啟動，記錄以容器上的 label 為準
+func recoverLeftovers(db storage.Store, runner podmanRunner, project string) {
+	managed, err := runner.ListManaged()
+	if err != nil {
+		log.Printf("Unable to check for leftover containers: %v", err)
+		return
+	}
+	existing := make(map[string]bool)
+	for _, summary := range managed {
+		existing[summary.Name()] = true
+	}
+
+	for _, summary := range managed {
+		backup := summary.Name()
+		name, ok := backupOf(backup)
+		if !ok || summary.Labels[container.LabelProject] != project {
+			continue
+		}
+		if existing[name] {
+			fmt.Printf("🧹 Removing %s left by an interrupted atomic deployment...\n", backup)
+			if err := runner.Run("rm", "-f", backup); err != nil {
+				log.Printf("Unable to remove leftover container %s: %v", backup, err)
+			}
+			continue
+		}
+
+		fmt.Printf("↩️  Restoring %s left by an interrupted atomic deployment...\n", name)
+		if err := runner.Run("rename", backup, name); err != nil {
+			log.Printf("Unable to restore container %s: %v", name, err)
+			continue
+		}
+		switch running, known := wasRunning(db, project, name); {
+		case running:
+			if err := runner.Run("start", name); err != nil {
+				log.Printf("Unable to start restored container %s: %v", name, err)
+			}
+		case !known:
+			fmt.Printf("⚠️  %s was not known to be running before the interrupted deployment and is left stopped\n", name)
+		}
+		// 既有的記錄可能屬於未完成的新容器，設定雜湊與版本改用舊容器的 label
+		state := model.ContainerState{Name: name}
+		if record, err := db.GetContainer(name); err == nil {
+			state = *record
+		}
+		if inspection, err := runner.Inspect(name); err == nil {
+			inspection.ApplyLabels(&state)
+		}
+		recordInspected(db, runner, state)
+		recordEvent(db, project, name, model.EventRestored, "left over from an interrupted atomic deployment")
+	}
+}
+
+// wasRunning 依最近一次 set_aside 事件回傳 atomic 部署保留 name 的舊容器前該容器是否執行中；
+// 沒有事件時 known 為 false
+func wasRunning(db storage.Store, project, name string) (running, known bool) {
+	events, err := db.GetEvents(project)
+	if err != nil {
+		log.Printf("Unable to read events of project %s: %v", project, err)
+		return false, false
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Container == name && events[i].Type == model.EventSetAside {
+			return events[i].Message == "running", true
+		}
+	}
+	return false, false
+}
+
+// podman 執行 podman 指令，失敗時回傳 stderr 內容
+func podman(args ...string) error {
+	cmd := exec.Command("podman", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("podman %s failed: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
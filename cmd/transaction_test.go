@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/vvvdwbvvv/rover/internal/container"
+	"github.com/vvvdwbvvv/rover/pkg/model"
+	"github.com/vvvdwbvvv/rover/pkg/storage"
+)
+
+// fakePodman 在記憶體中模擬 podman 的容器，並記錄執行過的指令
+type fakePodman struct {
+	containers map[string]*fakeContainer
+	calls      []string
+}
+
+type fakeContainer struct {
+	running bool
+	project string
+}
+
+func newFakePodman() *fakePodman {
+	return &fakePodman{containers: make(map[string]*fakeContainer)}
+}
+
+func (f *fakePodman) add(name string, running bool) {
+	f.containers[name] = &fakeContainer{running: running, project: "demo"}
+}
+
+func (f *fakePodman) Inspect(name string) (*container.Inspection, error) {
+	c, ok := f.containers[name]
+	if !ok {
+		return nil, container.ErrNoSuchContainer
+	}
+	inspection := &container.Inspection{Name: name}
+	inspection.State.Running = c.running
+	inspection.State.Status = "exited"
+	if c.running {
+		inspection.State.Status = "running"
+	}
+	inspection.Config.Labels = map[string]string{container.LabelProject: c.project}
+	return inspection, nil
+}
+
+func (f *fakePodman) ListManaged() ([]container.Summary, error) {
+	var summaries []container.Summary
+	for _, name := range sortedKeys(f.names()) {
+		summaries = append(summaries, container.Summary{
+			Names:  []string{name},
+			Labels: map[string]string{container.LabelProject: f.containers[name].project},
+		})
+	}
+	return summaries, nil
+}
+
+func (f *fakePodman) Run(args ...string) error {
+	f.calls = append(f.calls, strings.Join(args, " "))
+	name := args[len(args)-1]
+	switch args[0] {
+	case "rename":
+		name = args[1]
+	}
+	c, ok := f.containers[name]
+	if !ok {
+		if args[0] == "rm" && args[1] == "-f" {
+			return nil
+		}
+		return fmt.Errorf("podman %s failed: no such container %s", args[0], name)
+	}
+
+	switch args[0] {
+	case "stop":
+		c.running = false
+	case "start":
+		c.running = true
+	case "rm":
+		delete(f.containers, name)
+	case "rename":
+		delete(f.containers, name)
+		f.containers[args[2]] = c
+	default:
+		return errors.New("unexpected podman command " + args[0])
+	}
+	return nil
+}
+
+// names 回傳目前存在的容器及其是否執行中
+func (f *fakePodman) names() map[string]bool {
+	names := make(map[string]bool)
+	for name, c := range f.containers {
+		names[name] = c.running
+	}
+	return names
+}
+
+func TestTransactionRollback(t *testing.T) {
+	tests := []struct {
+		name         string
+		previous     *model.ContainerStatus // nil 表示部署前沒有容器
+		wantRestored []string
+		wantLive     map[string]bool
+		wantCalls    []string
+		wantStatus   model.ContainerStatus // 還原後的記錄；空字串表示記錄已刪除
+	}{
+		{
+			name:      "no previous container",
+			wantLive:  map[string]bool{},
+			wantCalls: []string{"rm -f web"},
+		},
+		{
+			name:         "previous stopped",
+			previous:     statusPtr(model.StatusExited),
+			wantRestored: []string{"web"},
+			wantLive:     map[string]bool{"web": false},
+			wantCalls:    []string{"rename web web-rover-previous", "rm -f web", "rename web-rover-previous web"},
+			wantStatus:   model.StatusExited,
+		},
+		{
+			name:         "previous running",
+			previous:     statusPtr(model.StatusRunning),
+			wantRestored: []string{"web"},
+			wantLive:     map[string]bool{"web": true},
+			wantCalls: []string{"stop web", "rename web web-rover-previous", "rm -f web",
+				"rename web-rover-previous web", "start web"},
+			wantStatus: model.StatusRunning,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := storage.NewMemory()
+			runner := newFakePodman()
+			if tt.previous != nil {
+				runner.add("web", *tt.previous == model.StatusRunning)
+				must(t, db.SaveContainer(model.ContainerState{Name: "web", Project: "demo", Status: *tt.previous, Revision: 1}))
+			}
+
+			tx := newTransaction(db, runner, "demo")
+			must(t, tx.replace("web"))
+			// 新容器啟動後失敗
+			runner.add("web", false)
+			must(t, db.SaveContainer(model.ContainerState{Name: "web", Project: "demo", Status: model.StatusFailed, Revision: 2}))
+
+			restored := tx.rollback()
+			if !reflect.DeepEqual(restored, tt.wantRestored) {
+				t.Errorf("rollback() restored %v, want %v", restored, tt.wantRestored)
+			}
+			if live := runner.names(); !reflect.DeepEqual(live, tt.wantLive) {
+				t.Errorf("containers after rollback = %v, want %v", live, tt.wantLive)
+			}
+			if !reflect.DeepEqual(runner.calls, tt.wantCalls) {
+				t.Errorf("podman calls = %q, want %q", runner.calls, tt.wantCalls)
+			}
+
+			record, err := db.GetContainer("web")
+			switch {
+			case tt.wantStatus == "":
+				if !errors.Is(err, storage.ErrContainerNotFound) {
+					t.Errorf("record after rollback = %+v, %v, want it deleted", record, err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case record.Status != tt.wantStatus || record.Revision != 1:
+				t.Errorf("record after rollback = %s revision %d, want %s revision 1", record.Status, record.Revision, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestTransactionCommit(t *testing.T) {
+	db := storage.NewMemory()
+	runner := newFakePodman()
+	runner.add("web", true)
+
+	tx := newTransaction(db, runner, "demo")
+	must(t, tx.replace("web"))
+	must(t, tx.replace("db"))
+	runner.add("web", true)
+	runner.add("db", true)
+	runner.calls = nil
+
+	tx.commit()
+	if want := []string{"rm web-rover-previous"}; !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("podman calls = %q, want %q", runner.calls, want)
+	}
+	if live, want := runner.names(), map[string]bool{"web": true, "db": true}; !reflect.DeepEqual(live, want) {
+		t.Errorf("containers after commit = %v, want %v", live, want)
+	}
+}
+
+func TestRecoverLeftovers(t *testing.T) {
+	db := storage.NewMemory()
+	runner := newFakePodman()
+	runner.add("web", true)
+	runner.add("worker", false)
+	runner.add("db", true)
+
+	// 部署在取代 web、worker、db 之後中斷，只有 db 的新容器已建立
+	tx := newTransaction(db, runner, "demo")
+	for _, name := range []string{"web", "worker", "db"} {
+		must(t, tx.replace(name))
+	}
+	runner.add("db", true)
+	// 沒有 set_aside 事件的舊容器與其他專案的舊容器
+	runner.add("cache-rover-previous", false)
+	runner.add("other-rover-previous", false)
+	runner.containers["other-rover-previous"].project = "other"
+	runner.calls = nil
+
+	recoverLeftovers(db, runner, "demo")
+
+	want := map[string]bool{"web": true, "worker": false, "db": true, "cache": false, "other-rover-previous": false}
+	if live := runner.names(); !reflect.DeepEqual(live, want) {
+		t.Errorf("containers after recovery = %v, want %v", live, want)
+	}
+	if !slices.Contains(runner.calls, "rm -f db-rover-previous") {
+		t.Errorf("podman calls = %q, want the leftover of db removed", runner.calls)
+	}
+	for _, name := range []string{"worker", "cache"} {
+		if slices.Contains(runner.calls, "start "+name) {
+			t.Errorf("%s was started although it was not known to be running", name)
+		}
+	}
+
+	events, err := db.GetEvents("demo")
+	must(t, err)
+	var restored []string
+	for _, event := range events {
+		if event.Type == model.EventRestored {
+			restored = append(restored, event.Container)
+		}
+	}
+	sort.Strings(restored)
+	if want := []string{"cache", "web", "worker"}; !reflect.DeepEqual(restored, want) {
+		t.Errorf("restored events for %v, want %v", restored, want)
+	}
+}
+
+func TestDeploySummary(t *testing.T) {
+	revision := &model.Revision{Project: "demo", Number: 4}
+	set := func(names ...string) map[string]bool {
+		m := make(map[string]bool)
+		for _, name := range names {
+			m[name] = true
+		}
+		return m
+	}
+
+	tests := []struct {
+		name   string
+		result deployResult
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "succeeded",
+			result: deployResult{revision: revision, started: set("web"), unchanged: set("db", "cache")},
+			want:   "✅ All containers started successfully (revision 4: 1 recreated, 2 unchanged)\n",
+			wantOK: true,
+		},
+		{
+			name:   "failed",
+			result: deployResult{revision: revision, started: set("db"), unchanged: set("cache"), failed: set("web", "worker")},
+			want:   "❌ Revision 4 failed: web, worker\n🔹 Started: db\n🔹 Unchanged: cache\n",
+		},
+		{
+			name:   "rolled back",
+			result: deployResult{revision: revision, failed: set("web"), rolledBack: true, restored: []string{"db", "web"}},
+			want:   "❌ Revision 4 failed: web\n↩️  Rolled back: new containers removed, restored db, web\n",
+		},
+		{
+			name:   "rolled back without previous containers",
+			result: deployResult{revision: revision, failed: set("web"), rolledBack: true},
+			want:   "❌ Revision 4 failed: web\n↩️  Rolled back: new containers removed, no previous containers to restore\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.result.summary()
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("summary() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func statusPtr(status model.ContainerStatus) *model.ContainerStatus {
+	return &status
+}
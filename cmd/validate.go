@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/vvvdwbvvv/rover/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// validateCmd 以 JSON Schema 驗證設定檔
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate a compose file against its JSON Schema",
+	Long:  `Validate rover-compose.{yaml,toml,json} against the Rover JSON Schema, or a docker-compose file against the compose-spec schema.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if printSchema, _ := cmd.Flags().GetBool("print-schema"); printSchema {
+			schema, err := config.GenerateSchema()
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(string(schema))
+			return
+		}
+
+		filePath := configFile(cmd)
+		problems, err := config.ValidateFile(filePath)
+		if err != nil {
+			log.Fatalf("Validate failed: %v", err)
+		}
+
+		if len(problems) > 0 {
+			for _, problem := range problems {
+				fmt.Println("❌", problem.Error())
+			}
+			os.Exit(1)
+		}
+		fmt.Printf("✅ %s is valid\n", filePath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringP("file", "f", "", "Compose file (searched in the current and parent directories by default)")
+	validateCmd.Flags().Bool("print-schema", false, "Print the rover-compose JSON Schema and exit")
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/compose-spec/compose-go/loader"
+	"gopkg.in/yaml.v3"
+)
+
+// BuildConfig 定義服務的 build 區段；短格式為 context 路徑字串
+type BuildConfig struct {
+	Context    string            `yaml:"context,omitempty" toml:"context,omitempty" json:"context,omitempty"`
+	Dockerfile string            `yaml:"dockerfile,omitempty" toml:"dockerfile,omitempty" json:"dockerfile,omitempty"`
+	Args       map[string]string `yaml:"args,omitempty" toml:"args,omitempty" json:"args,omitempty"`
+	Target     string            `yaml:"target,omitempty" toml:"target,omitempty" json:"target,omitempty"`
+	Labels     map[string]string `yaml:"labels,omitempty" toml:"labels,omitempty" json:"labels,omitempty"`
+	CacheFrom  []string          `yaml:"cache_from,omitempty" toml:"cache_from,omitempty" json:"cache_from,omitempty"`
+}
+
+// buildConfig 避免 Unmarshal 遞迴呼叫自身
+type buildConfig BuildConfig
+
+func (b *BuildConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*b = BuildConfig{Context: value.Value}
+		return nil
+	}
+	return value.Decode((*buildConfig)(b))
+}
+
+func (b *BuildConfig) UnmarshalJSON(data []byte) error {
+	var context string
+	if err := json.Unmarshal(data, &context); err == nil {
+		*b = BuildConfig{Context: context}
+		return nil
+	}
+	return json.Unmarshal(data, (*buildConfig)(b))
+}
+
+func (b *BuildConfig) UnmarshalTOML(raw interface{}) error {
+	if context, ok := raw.(string); ok {
+		*b = BuildConfig{Context: context}
+		return nil
+	}
+	// TOML 與 JSON 的欄位名稱相同，透過 JSON 轉換到結構
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, (*buildConfig)(b))
+}
+
+// DockerfilePath 回傳 Dockerfile 的路徑；相對路徑以 context 為基準，context 為 BuildContext 轉換後的路徑
+func (b BuildConfig) DockerfilePath(context string) string {
+	dockerfile := b.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	if filepath.IsAbs(dockerfile) {
+		return dockerfile
+	}
+	return filepath.Join(context, dockerfile)
+}
+
+// ImageName 回傳服務使用的映像檔；只有 build 區段時以「專案-服務」命名，確保同一專案的標籤固定
+func (s Service) ImageName(project string) string {
+	if s.Image != "" || s.Build == nil {
+		return s.Image
+	}
+	return loader.NormalizeProjectName(project + "-" + strings.ToLower(s.Name))
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/compose-spec/compose-go/interpolation"
+	"github.com/compose-spec/compose-go/loader"
+	"github.com/compose-spec/compose-go/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseCompose 透過 compose-go 解析 docker-compose 檔案，並轉換為 RoverCompose
+func ParseCompose(filePath string) (RoverCompose, error) {
+	var config RoverCompose
+
+	// 讀取 YAML 檔案
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return config, err
+	}
+
+	// 轉換為 map[string]interface{}
+	var composeFile map[string]interface{}
+	if err := yaml.Unmarshal(data, &composeFile); err != nil {
+		return config, err
+	}
+
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return config, err
+	}
+	workingDir := filepath.Dir(absPath)
+
+	// 取得環境變數
+	envs := map[string]string{}
+	for _, e := range os.Environ() {
+		parts := strings.SplitN(e, "=", 2)
+		if len(parts) == 2 {
+			envs[parts[0]] = parts[1]
+		}
+	}
+
+	// 解析 Compose 配置；先載入所有 profile 的服務，之後再由 ApplyProfiles 過濾
+	project, err := loader.Load(types.ConfigDetails{
+		WorkingDir: workingDir,
+		ConfigFiles: []types.ConfigFile{
+			{Filename: filePath, Content: data, Config: composeFile},
+		},
+		Environment: envs,
+	}, func(options *loader.Options) {
+		options.SkipNormalization = true
+		options.ResolvePaths = false // 相對路徑由 RoverCompose.HostPath 等在使用時轉換
+		options.Interpolate = &interpolation.Options{}
+		options.SkipValidation = false
+		options.Profiles = []string{"*"}
+		options.SetProjectName(loader.NormalizeProjectName(filepath.Base(workingDir)), false)
+	})
+	if err != nil {
+		return config, err
+	}
+
+	config.Name = project.Name
+	config.Services = make(map[string]Service, len(project.Services))
+	for _, s := range project.Services {
+		config.Services[s.Name] = fromComposeService(s)
+	}
+
+	// 頂層 secrets / configs 只保留內容來源；provider 以 x-rover-provider / x-rover-key 擴充欄位指定
+	for name, s := range project.Secrets {
+		if config.Secrets == nil {
+			config.Secrets = map[string]FileObject{}
+		}
+		config.Secrets[name] = fromComposeFileObject(types.FileObjectConfig(s))
+	}
+	for name, c := range project.Configs {
+		if config.Configs == nil {
+			config.Configs = map[string]FileObject{}
+		}
+		config.Configs[name] = fromComposeFileObject(types.FileObjectConfig(c))
+	}
+
+	// secret providers 定義於 x-rover-secret-providers
+	if raw, ok := project.Extensions["x-rover-secret-providers"]; ok {
+		if err := decodeExtension(raw, &config.SecretProviders); err != nil {
+			return config, fmt.Errorf("x-rover-secret-providers: %v", err)
+		}
+	}
+
+	return config, nil
+}
+
+// fromComposeFileObject 將 compose-go 的 secret / config 定義轉換為 FileObject
+func fromComposeFileObject(o types.FileObjectConfig) FileObject {
+	object := FileObject{File: o.File, Environment: o.Environment, Content: o.Content}
+	object.Provider, _ = o.Extensions["x-rover-provider"].(string)
+	object.Key, _ = o.Extensions["x-rover-key"].(string)
+	return object
+}
+
+// decodeExtension 將 x- 擴充欄位的內容轉換到結構，欄位名稱與 JSON 相同
+func decodeExtension(raw interface{}, v interface{}) error {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// fromComposeService 將 compose-go 的服務定義轉換為 Rover 的 Service
+func fromComposeService(s types.ServiceConfig) Service {
+	service := Service{
+		Name:        s.Name,
+		Image:       s.Image,
+		Command:     s.Command,
+		NetworkMode: s.NetworkMode,
+		Links:       s.Links,
+		VolumesFrom: s.VolumesFrom,
+		Restart:     s.Restart,
+		PullPolicy:  s.PullPolicy,
+		Profiles:    s.Profiles,
+	}
+
+	// 設置 build 區段
+	if s.Build != nil {
+		service.Build = &BuildConfig{
+			Context:    s.Build.Context,
+			Dockerfile: s.Build.Dockerfile,
+			Target:     s.Build.Target,
+			Labels:     s.Build.Labels,
+			CacheFrom:  s.Build.CacheFrom,
+		}
+		for key, value := range s.Build.Args {
+			if value == nil {
+				continue
+			}
+			if service.Build.Args == nil {
+				service.Build.Args = map[string]string{}
+			}
+			service.Build.Args[key] = *value
+		}
+	}
+
+	// 設置環境變數（未設定值且無法從環境取得的變數直接略過）
+	for key, value := range s.Environment {
+		if value == nil {
+			continue
+		}
+		if service.Environment == nil {
+			service.Environment = map[string]string{}
+		}
+		service.Environment[key] = *value
+	}
+
+	// 設置端口，輸出為 compose short syntax: [host_ip:][published:]target[/protocol]
+	for _, port := range s.Ports {
+		spec := fmt.Sprintf("%d", port.Target)
+		if port.Published != "" {
+			spec = port.Published + ":" + spec
+		}
+		if port.HostIP != "" {
+			spec = port.HostIP + ":" + spec
+		}
+		if port.Protocol != "" && port.Protocol != "tcp" {
+			spec += "/" + port.Protocol
+		}
+		service.Ports = append(service.Ports, spec)
+	}
+
+	// 設置 volumes
+	for _, volume := range s.Volumes {
+		spec := volume.Target
+		if volume.Source != "" {
+			spec = volume.Source + ":" + spec
+		}
+		if volume.ReadOnly {
+			spec += ":ro"
+		}
+		service.Volumes = append(service.Volumes, spec)
+	}
+
+	// 設置 secrets / configs 的掛載
+	for _, secret := range s.Secrets {
+		service.Secrets = append(service.Secrets, FileReference{
+			Source: secret.Source, Target: secret.Target, UID: secret.UID, GID: secret.GID, Mode: secret.Mode,
+		})
+	}
+	for _, c := range s.Configs {
+		service.Configs = append(service.Configs, FileReference{
+			Source: c.Source, Target: c.Target, UID: c.UID, GID: c.GID, Mode: c.Mode,
+		})
+	}
+
+	// 保留 depends_on 的 condition / required / restart
+	for dep, d := range s.DependsOn {
+		dependency := ServiceDependency{
+			Condition: d.Condition,
+			Required:  d.Required,
+			Restart:   d.Restart,
+		}
+		if dependency.Condition == "" {
+			dependency.Condition = ServiceConditionStarted
+		}
+		if service.DependsOn == nil {
+			service.DependsOn = DependsOn{}
+		}
+		service.DependsOn[dep] = dependency
+	}
+
+	return service
+}
@@ -0,0 +1,383 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 支援的輸出格式
+const (
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+	FormatJSON = "json"
+)
+
+// FormatFromPath 依副檔名推斷輸出格式，無法判斷時回傳 YAML
+func FormatFromPath(filePath string) string {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".toml":
+		return FormatTOML
+	case ".json":
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// Convert 讀取任一支援的設定檔，將正規化後的專案輸出為指定格式；
+// 欄位順序沿用原始檔案，註解在 YAML 與 TOML 之間保留
+func Convert(filePath, format string) ([]byte, error) {
+	project, err := LoadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := readLayout(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(project, format, source)
+}
+
+// Marshal 將專案輸出為指定格式，欄位依型別定義的順序
+func Marshal(project RoverCompose, format string) ([]byte, error) {
+	return marshal(project, format, newLayout())
+}
+
+// marshal 將專案輸出為指定格式；source 為原始檔案的排版資訊
+func marshal(project RoverCompose, format string, source layout) ([]byte, error) {
+	node, err := projectNode(project)
+	if err != nil {
+		return nil, err
+	}
+	source.arrange(node, "")
+
+	var buf bytes.Buffer
+	switch format {
+	case FormatYAML:
+		document := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}}
+		if c, ok := source.comments[""]; ok {
+			document.HeadComment = yamlComment(c.head)
+		}
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(document); err != nil {
+			return nil, err
+		}
+		encoder.Close()
+	case FormatJSON:
+		writeJSON(&buf, node, "")
+		buf.WriteString("\n")
+	case FormatTOML:
+		if c, ok := source.comments[""]; ok {
+			writeTOMLComment(&buf, c.head)
+			buf.WriteString("\n")
+		}
+		writeTOMLTable(&buf, node, nil, source.comments)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	return buf.Bytes(), nil
+}
+
+// readLayout 讀取原始檔案的欄位位置與註解
+func readLayout(filePath string) (layout, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return layout{}, err
+	}
+
+	switch FormatFromPath(filePath) {
+	case FormatTOML:
+		return tomlLayout(data), nil
+	case FormatJSON:
+		return jsonLayout(data), nil
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return layout{}, err
+	}
+	return yamlLayout(&node), nil
+}
+
+// projectNode 將專案編碼為 YAML 節點樹，並移除可由 map key 推得的服務名稱與空的 version
+func projectNode(project RoverCompose) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(project); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "version":
+			if value.Value == "" {
+				node.Content = append(node.Content[:i], node.Content[i+2:]...)
+				i -= 2
+			}
+		case "services":
+			for j := 1; j < len(value.Content); j += 2 {
+				removeKey(value.Content[j], "name")
+			}
+		}
+	}
+	return &node, nil
+}
+
+func removeKey(mapping *yaml.Node, name string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// arrange 依原始檔案的位置排序 mapping 的欄位並附上註解；原始檔案沒有的欄位排在最後
+func (l layout) arrange(node *yaml.Node, path string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		type pair struct{ key, value *yaml.Node }
+		pairs := make([]pair, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			pairs = append(pairs, pair{node.Content[i], node.Content[i+1]})
+		}
+		sort.SliceStable(pairs, func(i, j int) bool {
+			a, okA := l.positions[joinPath(path, pairs[i].key.Value)]
+			b, okB := l.positions[joinPath(path, pairs[j].key.Value)]
+			if okA != okB {
+				return okA
+			}
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Column < b.Column
+		})
+
+		node.Content = node.Content[:0]
+		for _, p := range pairs {
+			child := joinPath(path, p.key.Value)
+			if c, ok := l.comments[child]; ok {
+				p.key.HeadComment = yamlComment(c.head)
+				if p.value.Kind == yaml.ScalarNode {
+					p.value.LineComment = yamlComment(c.line)
+				} else {
+					p.key.LineComment = yamlComment(c.line)
+				}
+			}
+			l.arrange(p.value, child)
+			node.Content = append(node.Content, p.key, p.value)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			child := joinPath(path, strconv.Itoa(i))
+			if c, ok := l.comments[child]; ok {
+				item.HeadComment = yamlComment(c.head)
+				item.LineComment = yamlComment(c.line)
+			}
+			l.arrange(item, child)
+		}
+	}
+}
+
+// yamlComment 將註解內容轉為 yaml.v3 的註解格式
+func yamlComment(text string) string {
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = "# " + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeJSON 依節點順序輸出縮排的 JSON
+func writeJSON(buf *bytes.Buffer, node *yaml.Node, indent string) {
+	inner := indent + "  "
+	switch node.Kind {
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, _ := json.Marshal(node.Content[i].Value)
+			buf.WriteString(inner)
+			buf.Write(key)
+			buf.WriteString(": ")
+			writeJSON(buf, node.Content[i+1], inner)
+			if i+2 < len(node.Content) {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "}")
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[\n")
+		for i, item := range node.Content {
+			buf.WriteString(inner)
+			writeJSON(buf, item, inner)
+			if i+1 < len(node.Content) {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "]")
+	default:
+		buf.WriteString(scalarJSON(node))
+	}
+}
+
+func scalarJSON(node *yaml.Node) string {
+	switch node.ShortTag() {
+	case "!!int", "!!float", "!!bool":
+		return node.Value
+	case "!!null":
+		return "null"
+	}
+	value, _ := json.Marshal(node.Value)
+	return string(value)
+}
+
+var tomlBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// writeTOMLTable 輸出一個 table：先輸出純值欄位，再以 [a.b] 輸出子 table
+func writeTOMLTable(buf *bytes.Buffer, node *yaml.Node, path []string, comments map[string]comment) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if value.Kind == yaml.MappingNode || value.ShortTag() == "!!null" {
+			continue
+		}
+		c := comments[strings.Join(append(path, key.Value), ".")]
+		writeTOMLComment(buf, c.head)
+		buf.WriteString(tomlKeyString(key.Value) + " = " + tomlValue(value))
+		writeTOMLLineComment(buf, c.line)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if value.Kind != yaml.MappingNode {
+			continue
+		}
+		childPath := append(append([]string{}, path...), key.Value)
+		c := comments[strings.Join(childPath, ".")]
+
+		// 只含子 table 的 table 可省略標頭
+		if hasTOMLValues(value) || len(value.Content) == 0 || c != (comment{}) {
+			if buf.Len() > 0 {
+				buf.WriteString("\n")
+			}
+			writeTOMLComment(buf, c.head)
+			parts := make([]string, len(childPath))
+			for j, part := range childPath {
+				parts[j] = tomlKeyString(part)
+			}
+			buf.WriteString("[" + strings.Join(parts, ".") + "]")
+			writeTOMLLineComment(buf, c.line)
+		}
+		writeTOMLTable(buf, value, childPath, comments)
+	}
+}
+
+func hasTOMLValues(node *yaml.Node) bool {
+	for i := 1; i < len(node.Content); i += 2 {
+		if node.Content[i].Kind != yaml.MappingNode {
+			return true
+		}
+	}
+	return false
+}
+
+func writeTOMLComment(buf *bytes.Buffer, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		buf.WriteString("# " + line + "\n")
+	}
+}
+
+func writeTOMLLineComment(buf *bytes.Buffer, text string) {
+	if text != "" {
+		buf.WriteString(" # " + strings.ReplaceAll(text, "\n", " "))
+	}
+	buf.WriteString("\n")
+}
+
+// tomlValue 以行內格式輸出純值、陣列與 inline table
+func tomlValue(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			items = append(items, tomlValue(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case yaml.MappingNode:
+		fields := make([]string, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			fields = append(fields, tomlKeyString(node.Content[i].Value)+" = "+tomlValue(node.Content[i+1]))
+		}
+		if len(fields) == 0 {
+			return "{}"
+		}
+		return "{ " + strings.Join(fields, ", ") + " }"
+	}
+
+	switch node.ShortTag() {
+	case "!!int", "!!float", "!!bool":
+		return node.Value
+	}
+	return tomlString(node.Value)
+}
+
+func tomlKeyString(key string) string {
+	if tomlBareKey.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+// tomlString 輸出 TOML basic string
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// roundTripSource 服務與欄位刻意不依字母順序排列，並在各層加上註解
+const roundTripSource = `# Demo project
+name: demo
+services:
+  # Web frontend
+  web:
+    image: nginx:1.25 # pinned
+    ports:
+      - "8080:80"
+    depends_on:
+      - db
+    environment:
+      MODE: production
+  # Database
+  db:
+    image: postgres:16
+    restart: always
+`
+
+// roundTripTOML 與 roundTripSource 相同的專案，以 TOML 撰寫
+const roundTripTOML = `# Demo project
+name = "demo"
+
+# Web frontend
+[services.web]
+image = "nginx:1.25" # pinned
+ports = ["8080:80"]
+depends_on = ["db"]
+
+[services.web.environment]
+MODE = "production"
+
+# Database
+[services.db]
+image = "postgres:16"
+restart = "always"
+`
+
+// writeFile 在暫存目錄寫入設定檔並回傳路徑
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// convertFile 轉換設定檔並回傳輸出
+func convertFile(t *testing.T, path, format string) string {
+	t.Helper()
+	data, err := Convert(path, format)
+	if err != nil {
+		t.Fatalf("Convert(%s, %s) failed: %v", filepath.Base(path), format, err)
+	}
+	return string(data)
+}
+
+// assertOrder 確認 keys 依序出現在 output 中
+func assertOrder(t *testing.T, output string, keys ...string) {
+	t.Helper()
+	rest := output
+	for _, key := range keys {
+		i := strings.Index(rest, key)
+		if i < 0 {
+			t.Fatalf("%q missing or out of order in output:\n%s", key, output)
+		}
+		rest = rest[i+len(key):]
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string // 原始檔名
+		source   string // 原始內容
+		via      string // 中間格式
+		comments bool   // 中間格式是否保留註解
+	}{
+		{name: "yaml via toml", file: "rover-compose.yaml", source: roundTripSource, via: FormatTOML, comments: true},
+		{name: "yaml via json", file: "rover-compose.yaml", source: roundTripSource, via: FormatJSON},
+		{name: "yaml via yaml", file: "rover-compose.yaml", source: roundTripSource, via: FormatYAML, comments: true},
+		{name: "toml via yaml", file: "rover-compose.toml", source: roundTripTOML, via: FormatYAML, comments: true},
+		{name: "toml via json", file: "rover-compose.toml", source: roundTripTOML, via: FormatJSON},
+		{name: "toml via toml", file: "rover-compose.toml", source: roundTripTOML, via: FormatTOML, comments: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			source := writeFile(t, dir, tt.file, tt.source)
+
+			converted := convertFile(t, source, tt.via)
+			assertOrder(t, converted, "name", "services", "web", "image", "ports", "depends_on", "environment", "postgres", "restart")
+			if tt.comments {
+				for _, c := range []string{"# Demo project", "# Web frontend", "# pinned", "# Database"} {
+					if !strings.Contains(converted, c) {
+						t.Errorf("comment %q lost in %s output:\n%s", c, tt.via, converted)
+					}
+				}
+			}
+
+			middle := writeFile(t, t.TempDir(), "rover-compose."+tt.via, converted)
+			if again := convertFile(t, middle, tt.via); again != converted {
+				t.Errorf("second conversion to %s differs:\n--- first\n%s--- second\n%s", tt.via, converted, again)
+			}
+
+			// 轉回原始格式：保留註解的中間格式應還原出與直接轉換相同的內容
+			sourceFormat := FormatFromPath(tt.file)
+			back := convertFile(t, middle, sourceFormat)
+			assertOrder(t, back, "name", "services", "web", "image", "ports", "depends_on", "environment", "postgres", "restart")
+			if tt.comments && back != convertFile(t, source, sourceFormat) {
+				t.Errorf("%s after %s round trip differs from direct conversion:\n%s", sourceFormat, tt.via, back)
+			}
+
+			original, _ := LoadFile(source)
+			restored, err := LoadFile(middle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			originalHash, _ := ServiceHash(original.Services["web"])
+			restoredHash, _ := ServiceHash(restored.Services["web"])
+			if originalHash != restoredHash {
+				t.Errorf("service web changed after conversion to %s", tt.via)
+			}
+		})
+	}
+}
+
+func TestConvertIdempotent(t *testing.T) {
+	for _, format := range []string{FormatYAML, FormatTOML, FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			source := writeFile(t, t.TempDir(), "rover-compose.yaml", roundTripSource)
+			first := convertFile(t, source, format)
+			second := convertFile(t, writeFile(t, t.TempDir(), "rover-compose."+format, first), format)
+			if first != second {
+				t.Errorf("converting the %s output again changed it:\n--- first\n%s--- second\n%s", format, first, second)
+			}
+		})
+	}
+}
+
+func TestConvertUnsupportedFormat(t *testing.T) {
+	source := writeFile(t, t.TempDir(), "rover-compose.yaml", roundTripSource)
+	if _, err := Convert(source, "ini"); err == nil {
+		t.Fatal("expected an error for an unsupported format")
+	}
+}
@@ -1,22 +1,67 @@
 package config
 
 import (
-	"errors"
 	"fmt"
+	"sort"
+	"strings"
 )
 
+// CycleError 表示服務依賴中存在循環依賴，Path 為循環路徑（首尾為同一服務）
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("Detected circular dependency in depends_on: %s", strings.Join(e.Path, " -> "))
+}
+
+// GetServiceStartupOrder 回傳服務的啟動順序；沒有依賴關係的服務依名稱排序，結果固定
 func GetServiceStartupOrder(services map[string]Service) ([]string, error) {
+	levels, err := GetServiceStartupLevels(services)
+	if err != nil {
+		return nil, err
+	}
+
+	order := []string{}
+	for _, level := range levels {
+		order = append(order, level...)
+	}
+	return order, nil
+}
+
+// GetServiceShutdownOrder 回傳服務的停止順序，即啟動順序的反向
+func GetServiceShutdownOrder(services map[string]Service) ([]string, error) {
+	order, err := GetServiceStartupOrder(services)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	return order, nil
+}
+
+// GetServiceStartupLevels 回傳分層的啟動順序：同一層的服務彼此沒有依賴，可平行啟動
+func GetServiceStartupLevels(services map[string]Service) ([][]string, error) {
 	graph := make(map[string][]string)
 	inDegree := make(map[string]int)
 
+	names := make([]string, 0, len(services))
 	for name := range services {
+		names = append(names, name)
 		graph[name] = []string{}
 		inDegree[name] = 0
 	}
+	sort.Strings(names)
 
-	for name, service := range services {
-		for _, dep := range service.DependsOn {
+	for _, name := range names {
+		for _, dep := range services[name].DependencyNames() {
 			if _, exists := services[dep]; !exists {
+				// required: false 的依賴不存在時直接略過
+				if !services[name].Requires(dep) {
+					continue
+				}
 				return nil, fmt.Errorf("Service %s depends on unknown service %s", name, dep)
 			}
 			graph[dep] = append(graph[dep], name)
@@ -24,31 +69,83 @@ func GetServiceStartupOrder(services map[string]Service) ([]string, error) {
 		}
 	}
 
-	order := []string{}
-	queue := []string{}
-
-	for name, degree := range inDegree {
-		if degree == 0 {
-			queue = append(queue, name)
+	levels := [][]string{}
+	level := []string{}
+	for _, name := range names {
+		if inDegree[name] == 0 {
+			level = append(level, name)
 		}
 	}
 
-	for len(queue) > 0 {
-		node := queue[0]
-		queue = queue[1:]
-		order = append(order, node)
+	visited := 0
+	for len(level) > 0 {
+		levels = append(levels, level)
+		visited += len(level)
 
-		for _, neighbor := range graph[node] {
-			inDegree[neighbor]--
-			if inDegree[neighbor] == 0 {
-				queue = append(queue, neighbor)
+		next := []string{}
+		for _, node := range level {
+			for _, neighbor := range graph[node] {
+				inDegree[neighbor]--
+				if inDegree[neighbor] == 0 {
+					next = append(next, neighbor)
+				}
 			}
 		}
+		sort.Strings(next)
+		level = next
 	}
 
-	if len(order) != len(services) {
-		return nil, errors.New("Detected circular dependency in depends_on")
+	if visited != len(services) {
+		return nil, &CycleError{Path: findCycle(services, inDegree, names)}
 	}
 
-	return order, nil
+	return levels, nil
+}
+
+// findCycle 在拓撲排序後仍有入度的服務中，以 DFS 找出一條循環路徑
+func findCycle(services map[string]Service, inDegree map[string]int, names []string) []string {
+	const (
+		unvisited = iota
+		inStack
+		done
+	)
+	state := make(map[string]int)
+	stack := []string{}
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = inStack
+		stack = append(stack, name)
+
+		for _, dep := range services[name].DependencyNames() {
+			if inDegree[dep] == 0 {
+				continue
+			}
+			switch state[dep] {
+			case inStack:
+				for i, n := range stack {
+					if n == dep {
+						return append(append([]string{}, stack[i:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[name] = done
+		return nil
+	}
+
+	for _, name := range names {
+		if inDegree[name] > 0 && state[name] == unvisited {
+			if cycle := visit(name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
 }
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// servicesWith 依 name -> 依賴名稱建立服務，依賴皆為短格式
+func servicesWith(deps map[string][]string) map[string]Service {
+	services := make(map[string]Service, len(deps))
+	for name, names := range deps {
+		service := Service{Name: name}
+		if len(names) > 0 {
+			service.DependsOn = DependsOn{}
+			for _, dep := range names {
+				service.DependsOn[dep] = defaultDependency()
+			}
+		}
+		services[name] = service
+	}
+	return services
+}
+
+func TestGetServiceStartupLevels(t *testing.T) {
+	tests := []struct {
+		name string
+		deps map[string][]string
+		want [][]string
+	}{
+		{
+			name: "independent services sorted by name",
+			deps: map[string][]string{"web": nil, "cache": nil, "db": nil},
+			want: [][]string{{"cache", "db", "web"}},
+		},
+		{
+			name: "chain",
+			deps: map[string][]string{"web": {"api"}, "api": {"db"}, "db": nil},
+			want: [][]string{{"db"}, {"api"}, {"web"}},
+		},
+		{
+			name: "diamond",
+			deps: map[string][]string{"web": {"api", "worker"}, "api": {"db"}, "worker": {"db"}, "db": nil},
+			want: [][]string{{"db"}, {"api", "worker"}, {"web"}},
+		},
+		{
+			name: "empty",
+			deps: map[string][]string{},
+			want: [][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetServiceStartupLevels(servicesWith(tt.deps))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetServiceStartupOrderDeterministic(t *testing.T) {
+	services := servicesWith(map[string][]string{"web": {"db"}, "worker": {"db"}, "db": nil, "admin": nil})
+	want := []string{"admin", "db", "web", "worker"}
+	for i := 0; i < 20; i++ {
+		got, err := GetServiceStartupOrder(services)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("run %d: got %v, want %v", i, got, want)
+		}
+	}
+
+	shutdown, err := GetServiceShutdownOrder(services)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"worker", "web", "db", "admin"}; !reflect.DeepEqual(shutdown, want) {
+		t.Errorf("shutdown order %v, want %v", shutdown, want)
+	}
+}
+
+func TestGetServiceStartupLevelsCycle(t *testing.T) {
+	tests := []struct {
+		name string
+		deps map[string][]string
+		want []string
+	}{
+		{
+			name: "self",
+			deps: map[string][]string{"web": {"web"}},
+			want: []string{"web", "web"},
+		},
+		{
+			name: "two services",
+			deps: map[string][]string{"a": {"b"}, "b": {"a"}},
+			want: []string{"a", "b", "a"},
+		},
+		{
+			name: "cycle behind an acyclic service",
+			deps: map[string][]string{"web": {"api"}, "api": {"worker"}, "worker": {"queue"}, "queue": {"api"}, "db": nil},
+			want: []string{"api", "worker", "queue", "api"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetServiceStartupLevels(servicesWith(tt.deps))
+			var cycle *CycleError
+			if !errors.As(err, &cycle) {
+				t.Fatalf("expected a CycleError, got %v", err)
+			}
+			if !reflect.DeepEqual(cycle.Path, tt.want) {
+				t.Errorf("cycle path %v, want %v", cycle.Path, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetServiceStartupLevelsUnknownDependency(t *testing.T) {
+	services := servicesWith(map[string][]string{"web": {"db"}})
+	if _, err := GetServiceStartupLevels(services); err == nil {
+		t.Fatal("expected an error for a required dependency on an unknown service")
+	}
+
+	optional := services["web"]
+	optional.DependsOn["db"] = ServiceDependency{Condition: ServiceConditionStarted, Required: false}
+	services["web"] = optional
+	levels, err := GetServiceStartupLevels(services)
+	if err != nil {
+		t.Fatalf("optional dependency on an unknown service should be skipped: %v", err)
+	}
+	if want := [][]string{{"web"}}; !reflect.DeepEqual(levels, want) {
+		t.Errorf("got %v, want %v", levels, want)
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// depends_on 支援的 condition
+const (
+	ServiceConditionStarted               = "service_started"
+	ServiceConditionHealthy               = "service_healthy"
+	ServiceConditionCompletedSuccessfully = "service_completed_successfully"
+)
+
+// ServiceDependency 定義 depends_on 長格式中單一依賴的設定
+type ServiceDependency struct {
+	Condition string `yaml:"condition" toml:"condition" json:"condition"`
+	Required  bool   `yaml:"required" toml:"required" json:"required"`
+	Restart   bool   `yaml:"restart,omitempty" toml:"restart,omitempty" json:"restart,omitempty"`
+}
+
+// DependsOn 同時接受短格式（服務名稱列表）與長格式（服務名稱對應 condition/required/restart）
+type DependsOn map[string]ServiceDependency
+
+// defaultDependency 短格式的預設值，與 compose 規格一致
+func defaultDependency() ServiceDependency {
+	return ServiceDependency{Condition: ServiceConditionStarted, Required: true}
+}
+
+// Names 回傳依名稱排序的依賴服務
+func (d DependsOn) Names() []string {
+	names := make([]string, 0, len(d))
+	for name := range d {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isShortForm 所有依賴都是預設值時可用短格式輸出
+func (d DependsOn) isShortForm() bool {
+	for _, dep := range d {
+		if dep != defaultDependency() {
+			return false
+		}
+	}
+	return true
+}
+
+func (d DependsOn) MarshalYAML() (interface{}, error) {
+	if d.isShortForm() {
+		return d.Names(), nil
+	}
+	return map[string]ServiceDependency(d), nil
+}
+
+func (d DependsOn) MarshalJSON() ([]byte, error) {
+	if d.isShortForm() {
+		return json.Marshal(d.Names())
+	}
+	return json.Marshal(map[string]ServiceDependency(d))
+}
+
+func (d *DependsOn) UnmarshalYAML(value *yaml.Node) error {
+	var raw interface{}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	return d.fromValue(raw)
+}
+
+func (d *DependsOn) UnmarshalJSON(data []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	return d.fromValue(raw)
+}
+
+func (d *DependsOn) UnmarshalTOML(raw interface{}) error {
+	return d.fromValue(raw)
+}
+
+// fromValue 將解碼後的通用值轉換為 DependsOn，三種格式共用
+func (d *DependsOn) fromValue(raw interface{}) error {
+	deps := DependsOn{}
+
+	switch v := raw.(type) {
+	case nil:
+	case []interface{}:
+		for _, item := range v {
+			name, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("depends_on: expected service name, got %v", item)
+			}
+			deps[name] = defaultDependency()
+		}
+	case map[string]interface{}:
+		for name, item := range v {
+			dep := defaultDependency()
+			if item != nil {
+				fields, ok := item.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("depends_on.%s: expected a mapping, got %v", name, item)
+				}
+				for key, value := range fields {
+					var ok bool
+					switch key {
+					case "condition":
+						dep.Condition, ok = value.(string)
+					case "required":
+						dep.Required, ok = value.(bool)
+					case "restart":
+						dep.Restart, ok = value.(bool)
+					default:
+						return fmt.Errorf("depends_on.%s: unknown key %q", name, key)
+					}
+					if !ok {
+						return fmt.Errorf("depends_on.%s.%s: invalid value %v", name, key, value)
+					}
+				}
+			}
+			if err := validateCondition(dep.Condition); err != nil {
+				return fmt.Errorf("depends_on.%s: %v", name, err)
+			}
+			deps[name] = dep
+		}
+	default:
+		return fmt.Errorf("depends_on: expected a list or a mapping, got %v", raw)
+	}
+
+	*d = deps
+	return nil
+}
+
+func validateCondition(condition string) error {
+	switch condition {
+	case ServiceConditionStarted, ServiceConditionHealthy, ServiceConditionCompletedSuccessfully:
+		return nil
+	}
+	return fmt.Errorf("invalid condition %q", condition)
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDependsOnUnmarshal(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		want   DependsOn
+	}{
+		{
+			name:   "yaml short form",
+			format: FormatYAML,
+			input:  "depends_on: [db, cache]",
+			want:   DependsOn{"db": defaultDependency(), "cache": defaultDependency()},
+		},
+		{
+			name:   "yaml long form",
+			format: FormatYAML,
+			input:  "depends_on:\n  db:\n    condition: service_healthy\n    restart: true\n  cache:\n    required: false\n",
+			want: DependsOn{
+				"db":    {Condition: ServiceConditionHealthy, Required: true, Restart: true},
+				"cache": {Condition: ServiceConditionStarted, Required: false},
+			},
+		},
+		{
+			name:   "json short form",
+			format: FormatJSON,
+			input:  `{"depends_on": ["db"]}`,
+			want:   DependsOn{"db": defaultDependency()},
+		},
+		{
+			name:   "json long form",
+			format: FormatJSON,
+			input:  `{"depends_on": {"db": {"condition": "service_completed_successfully"}}}`,
+			want:   DependsOn{"db": {Condition: ServiceConditionCompletedSuccessfully, Required: true}},
+		},
+		{
+			name:   "toml short form",
+			format: FormatTOML,
+			input:  `depends_on = ["db"]`,
+			want:   DependsOn{"db": defaultDependency()},
+		},
+		{
+			name:   "toml long form",
+			format: FormatTOML,
+			input:  "[depends_on.db]\ncondition = \"service_healthy\"\nrequired = false\n",
+			want:   DependsOn{"db": {Condition: ServiceConditionHealthy, Required: false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeDependsOn(tt.format, tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDependsOnUnmarshalInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+	}{
+		{name: "unknown condition", format: FormatYAML, input: "depends_on:\n  db:\n    condition: service_ready\n"},
+		{name: "unknown key", format: FormatYAML, input: "depends_on:\n  db:\n    wait: true\n"},
+		{name: "wrong value type", format: FormatJSON, input: `{"depends_on": {"db": {"required": "yes"}}}`},
+		{name: "non-string name", format: FormatJSON, input: `{"depends_on": [1]}`},
+		{name: "scalar", format: FormatTOML, input: `depends_on = "db"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeDependsOn(tt.format, tt.input); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestDependsOnMarshal(t *testing.T) {
+	short := DependsOn{"db": defaultDependency(), "cache": defaultDependency()}
+	data, err := json.Marshal(short)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `["cache","db"]` {
+		t.Errorf("short form marshalled as %s", data)
+	}
+
+	long := DependsOn{"db": {Condition: ServiceConditionHealthy, Required: true}}
+	data, err = yaml.Marshal(struct {
+		DependsOn DependsOn `yaml:"depends_on"`
+	}{long})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeDependsOn(FormatYAML, string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, long) {
+		t.Errorf("long form round trip got %+v, want %+v", got, long)
+	}
+}
+
+// decodeDependsOn 以指定格式解碼含 depends_on 欄位的文件
+func decodeDependsOn(format, input string) (DependsOn, error) {
+	var doc struct {
+		DependsOn DependsOn `yaml:"depends_on" toml:"depends_on" json:"depends_on"`
+	}
+	var err error
+	switch format {
+	case FormatJSON:
+		err = json.Unmarshal([]byte(input), &doc)
+	case FormatTOML:
+		_, err = toml.Decode(input, &doc)
+	default:
+		err = yaml.Unmarshal([]byte(input), &doc)
+	}
+	return doc.DependsOn, err
+}
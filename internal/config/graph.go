@@ -0,0 +1,127 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// 依賴關係的來源
+const (
+	DependencyDependsOn   = "depends_on"
+	DependencyLinks       = "links"
+	DependencyNetworkMode = "network_mode"
+	DependencyVolumesFrom = "volumes_from"
+)
+
+// DependencyEdge 服務之間的一條依賴關係：From 依賴 To
+type DependencyEdge struct {
+	From      string
+	To        string
+	Kind      string
+	Condition string
+	Required  bool
+	Restart   bool
+}
+
+// Dependencies 回傳服務的所有依賴關係，來源包含 depends_on、links、
+// network_mode: service:x 與 volumes_from
+func (s Service) Dependencies() []DependencyEdge {
+	edges := []DependencyEdge{}
+	for _, name := range s.DependsOn.Names() {
+		dep := s.DependsOn[name]
+		edges = append(edges, DependencyEdge{
+			From:      s.Name,
+			To:        name,
+			Kind:      DependencyDependsOn,
+			Condition: dep.Condition,
+			Required:  dep.Required,
+			Restart:   dep.Restart,
+		})
+	}
+
+	// links 格式為 service 或 service:alias
+	for _, link := range s.Links {
+		name := strings.SplitN(link, ":", 2)[0]
+		edges = append(edges, DependencyEdge{From: s.Name, To: name, Kind: DependencyLinks, Required: true})
+	}
+
+	if name, ok := strings.CutPrefix(s.NetworkMode, "service:"); ok {
+		edges = append(edges, DependencyEdge{From: s.Name, To: name, Kind: DependencyNetworkMode, Required: true})
+	}
+
+	// volumes_from 格式為 service[:mode] 或 container:name[:mode]，後者不是服務
+	for _, from := range s.VolumesFrom {
+		if strings.HasPrefix(from, "container:") {
+			continue
+		}
+		name := strings.SplitN(from, ":", 2)[0]
+		edges = append(edges, DependencyEdge{From: s.Name, To: name, Kind: DependencyVolumesFrom, Required: true})
+	}
+
+	return edges
+}
+
+// DependencyNames 回傳依名稱排序、不重複的依賴服務
+func (s Service) DependencyNames() []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, edge := range s.Dependencies() {
+		if !seen[edge.To] {
+			seen[edge.To] = true
+			names = append(names, edge.To)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Requires 判斷依賴是否為必要；只有 depends_on 能以 required: false 標記為非必要
+func (s Service) Requires(name string) bool {
+	for _, edge := range s.Dependencies() {
+		if edge.To == name && edge.Required {
+			return true
+		}
+	}
+	return false
+}
+
+// DependencyGraph 回傳專案中所有服務的依賴關係，依服務名稱排序
+func (c RoverCompose) DependencyGraph() []DependencyEdge {
+	names := make([]string, 0, len(c.Services))
+	for name := range c.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	edges := []DependencyEdge{}
+	for _, name := range names {
+		edges = append(edges, c.Services[name].Dependencies()...)
+	}
+	return edges
+}
+
+// LinkAliases 回傳其他服務以 links 指定給 name 的別名，依名稱排序且不重複；
+// 未指定別名或別名與服務名稱相同的 link 不需要額外的別名
+func (c RoverCompose) LinkAliases(name string) []string {
+	seen := make(map[string]bool)
+	aliases := []string{}
+	for _, service := range c.Services {
+		for _, link := range service.Links {
+			target, alias, found := strings.Cut(link, ":")
+			if target != name || !found || alias == "" || alias == name || seen[alias] {
+				continue
+			}
+			seen[alias] = true
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// SupportsNetworkAliases 判斷 network_mode 是否可設定 network alias；
+// host、none 與共用其他容器網路的模式沒有自己的網路
+func (s Service) SupportsNetworkAliases() bool {
+	mode := s.NetworkMode
+	return !(mode == "host" || mode == "none" || strings.HasPrefix(mode, "service:") || strings.HasPrefix(mode, "container:"))
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLinkAliases(t *testing.T) {
+	project := RoverCompose{Services: map[string]Service{
+		"web":    {Name: "web", Links: []string{"db:database", "cache"}},
+		"worker": {Name: "worker", Links: []string{"db:database", "db:primary", "db:db"}},
+		"db":     {Name: "db"},
+		"cache":  {Name: "cache"},
+	}}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "db", want: []string{"database", "primary"}},
+		{name: "cache", want: []string{}},
+		{name: "web", want: []string{}},
+	}
+	for _, tt := range tests {
+		if got := project.LinkAliases(tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LinkAliases(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSupportsNetworkAliases(t *testing.T) {
+	tests := map[string]bool{
+		"":                true,
+		"bridge":          true,
+		"mynet":           true,
+		"host":            false,
+		"none":            false,
+		"service:db":      false,
+		"container:other": false,
+	}
+	for mode, want := range tests {
+		if got := (Service{NetworkMode: mode}).SupportsNetworkAliases(); got != want {
+			t.Errorf("network_mode %q: got %v, want %v", mode, got, want)
+		}
+	}
+}
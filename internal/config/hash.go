@@ -0,0 +1,18 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// ServiceHash 計算服務設定的雜湊值，用來判斷服務設定是否變更
+func ServiceHash(service Service) (string, error) {
+	// encoding/json 會依 key 排序 map，輸出結果固定
+	data, err := json.Marshal(service)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
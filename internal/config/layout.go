@@ -0,0 +1,278 @@
+package config
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// layout 記錄設定檔原始的排版資訊：每個路徑（以 . 串接）的位置與註解
+type layout struct {
+	positions map[string]Position
+	comments  map[string]comment
+}
+
+// comment 不含 # 的註解內容，多行以 \n 分隔
+type comment struct {
+	head string
+	line string
+}
+
+func newLayout() layout {
+	return layout{
+		positions: map[string]Position{"": {Line: 1, Column: 1}},
+		comments:  map[string]comment{},
+	}
+}
+
+// yamlLayout 走訪 YAML 節點樹，記錄每個路徑的位置與註解
+func yamlLayout(root *yaml.Node) layout {
+	l := newLayout()
+
+	var walk func(node *yaml.Node, path string)
+	walk = func(node *yaml.Node, path string) {
+		switch node.Kind {
+		case yaml.DocumentNode, yaml.AliasNode:
+			if node.HeadComment != "" {
+				l.comments[""] = comment{head: yamlCommentText(node.HeadComment)}
+			}
+			for _, child := range node.Content {
+				walk(child, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				child := joinPath(path, key.Value)
+				l.positions[child] = Position{Line: key.Line, Column: key.Column}
+				line := key.LineComment
+				if line == "" {
+					line = value.LineComment
+				}
+				l.record(child, key.HeadComment, line)
+				walk(value, child)
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				child := joinPath(path, strconv.Itoa(i))
+				l.positions[child] = Position{Line: item.Line, Column: item.Column}
+				l.record(child, item.HeadComment, item.LineComment)
+				walk(item, child)
+			}
+		}
+	}
+	walk(root, "")
+	return l
+}
+
+func (l layout) record(path, head, line string) {
+	if head == "" && line == "" {
+		return
+	}
+	l.comments[path] = comment{head: yamlCommentText(head), line: yamlCommentText(line)}
+}
+
+// yamlCommentText 去除 yaml.v3 註解中每行開頭的 #
+func yamlCommentText(text string) string {
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(line), "#"), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// jsonLayout 掃描 JSON 文件，記錄每個路徑的位置（JSON 沒有註解）
+func jsonLayout(data []byte) layout {
+	l := newLayout()
+	s := &jsonScanner{data: data}
+	s.value("", l.positions)
+	return l
+}
+
+type jsonScanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *jsonScanner) skipSpace() {
+	for s.pos < len(s.data) && strings.IndexByte(" \t\r\n", s.data[s.pos]) >= 0 {
+		s.pos++
+	}
+}
+
+func (s *jsonScanner) record(positions map[string]Position, path string) {
+	line, column := offsetPosition(s.data, s.pos)
+	positions[path] = Position{Line: line, Column: column}
+}
+
+func (s *jsonScanner) value(path string, positions map[string]Position) {
+	s.skipSpace()
+	if s.pos >= len(s.data) {
+		return
+	}
+
+	switch s.data[s.pos] {
+	case '{':
+		s.pos++
+		for {
+			s.skipSpace()
+			if s.pos >= len(s.data) || s.data[s.pos] == '}' {
+				s.pos++
+				return
+			}
+			if s.data[s.pos] == ',' {
+				s.pos++
+				continue
+			}
+			keyPos := s.pos
+			child := joinPath(path, s.str())
+			line, column := offsetPosition(s.data, keyPos)
+			positions[child] = Position{Line: line, Column: column}
+			s.skipSpace()
+			if s.pos < len(s.data) && s.data[s.pos] == ':' {
+				s.pos++
+			}
+			s.value(child, positions)
+		}
+	case '[':
+		s.pos++
+		for i := 0; ; {
+			s.skipSpace()
+			if s.pos >= len(s.data) || s.data[s.pos] == ']' {
+				s.pos++
+				return
+			}
+			if s.data[s.pos] == ',' {
+				s.pos++
+				continue
+			}
+			child := joinPath(path, strconv.Itoa(i))
+			s.record(positions, child)
+			s.value(child, positions)
+			i++
+		}
+	case '"':
+		s.str()
+	default:
+		for s.pos < len(s.data) && strings.IndexByte(",}] \t\r\n", s.data[s.pos]) < 0 {
+			s.pos++
+		}
+	}
+}
+
+// str 讀取一個 JSON 字串並回傳解碼後的內容
+func (s *jsonScanner) str() string {
+	start := s.pos
+	s.pos++
+	for s.pos < len(s.data) && s.data[s.pos] != '"' {
+		if s.data[s.pos] == '\\' {
+			s.pos++
+		}
+		s.pos++
+	}
+	s.pos++
+	if s.pos > len(s.data) {
+		s.pos = len(s.data)
+	}
+
+	var value string
+	json.Unmarshal(s.data[start:s.pos], &value)
+	return value
+}
+
+var (
+	tomlTablePattern = regexp.MustCompile(`^\s*\[\[?\s*([^\]]+?)\s*\]\]?`)
+	tomlKeyPattern   = regexp.MustCompile(`^(\s*)([A-Za-z0-9_\-."' ]+?)\s*=`)
+)
+
+// tomlLayout 逐行掃描 TOML 的 table 與 key，記錄每個路徑的位置與註解
+func tomlLayout(data []byte) layout {
+	l := newLayout()
+	arrayIndex := map[string]int{}
+	table := ""
+	var pending []string
+
+	for i, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			// 與 key 之間隔著空行的註解不歸屬於該 key；檔案開頭的註解視為整份文件的註解
+			if len(pending) > 0 && len(l.positions) == 1 {
+				l.comments[""] = comment{head: strings.Join(pending, "\n")}
+			}
+			pending = nil
+			continue
+		case strings.HasPrefix(trimmed, "#"):
+			pending = append(pending, strings.TrimPrefix(strings.TrimPrefix(trimmed, "#"), " "))
+			continue
+		}
+
+		path := ""
+		var column int
+		if m := tomlTablePattern.FindStringSubmatchIndex(line); m != nil {
+			parts := tomlKey(line[m[2]:m[3]])
+			table = strings.Join(parts, ".")
+			column = m[2] + 1
+			// 隱含定義的上層 table 以第一次出現的位置為準
+			for j := 1; j < len(parts); j++ {
+				parent := strings.Join(parts[:j], ".")
+				if _, ok := l.positions[parent]; !ok {
+					l.positions[parent] = Position{Line: i + 1, Column: column}
+				}
+			}
+			// [[array]] 的每個 table 以索引區分
+			if strings.HasPrefix(trimmed, "[[") {
+				index := arrayIndex[table]
+				arrayIndex[table]++
+				l.positions[table] = Position{Line: i + 1, Column: column}
+				table = joinPath(table, strconv.Itoa(index))
+			}
+			path = table
+		} else if m := tomlKeyPattern.FindStringSubmatchIndex(line); m != nil {
+			path = joinPath(table, strings.Join(tomlKey(line[m[4]:m[5]]), "."))
+			column = m[4] + 1
+		} else {
+			pending = nil
+			continue
+		}
+
+		l.positions[path] = Position{Line: i + 1, Column: column}
+		if c := (comment{head: strings.Join(pending, "\n"), line: tomlLineComment(line)}); c != (comment{}) {
+			l.comments[path] = c
+		}
+		pending = nil
+	}
+	return l
+}
+
+// tomlLineComment 取出行尾註解，略過字串中的 #
+func tomlLineComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return strings.TrimSpace(line[i+1:])
+		}
+	}
+	return ""
+}
+
+// tomlKey 拆解 dotted key，去除引號
+func tomlKey(key string) []string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return parts
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LockFileName 鎖定映像檔 digest 的檔案名稱，與設定檔放在同一目錄
+const LockFileName = "rover.lock"
+
+// lockFileVersion 目前的 lock 檔格式版本
+const lockFileVersion = 1
+
+// LockFile 記錄每個服務映像檔解析出的 digest
+type LockFile struct {
+	Version  int                    `json:"version"`
+	Services map[string]LockedImage `json:"services"`
+}
+
+// LockedImage 服務的映像檔與鎖定的 digest
+type LockedImage struct {
+	Image  string `json:"image"`
+	Digest string `json:"digest"`
+}
+
+// LockFilePath 回傳專案的 lock 檔路徑
+func LockFilePath(project RoverCompose) string {
+	return filepath.Join(project.WorkingDir, LockFileName)
+}
+
+// NewLockFile 建立空的 lock 檔
+func NewLockFile() LockFile {
+	return LockFile{Version: lockFileVersion, Services: make(map[string]LockedImage)}
+}
+
+// ReadLockFile 讀取 lock 檔；檔案不存在時 exists 為 false
+func ReadLockFile(path string) (lock LockFile, exists bool, err error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewLockFile(), false, nil
+	}
+	if err != nil {
+		return lock, false, err
+	}
+
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return lock, true, fmt.Errorf("invalid lock file %s: %v", path, err)
+	}
+	if lock.Version != lockFileVersion {
+		return lock, true, fmt.Errorf("unsupported lock file version %d in %s", lock.Version, path)
+	}
+	if lock.Services == nil {
+		lock.Services = make(map[string]LockedImage)
+	}
+	return lock, true, nil
+}
+
+// Write 將 lock 檔寫入 path；encoding/json 依 key 排序，輸出結果固定
+func (l LockFile) Write(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// LockableServices 回傳需要鎖定 digest 的服務：有 image 且不是由 build 區段建置
+func LockableServices(project RoverCompose) []string {
+	var names []string
+	for name, service := range project.Services {
+		if service.Image != "" && service.Build == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stale 回傳 lock 檔與專案不一致之處：未鎖定的服務、映像檔已變更，以及已不存在的服務
+func (l LockFile) Stale(project RoverCompose) []string {
+	var problems []string
+	lockable := make(map[string]bool)
+	for _, name := range LockableServices(project) {
+		lockable[name] = true
+		locked, ok := l.Services[name]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("service %s is not locked", name))
+		case locked.Image != project.Services[name].Image:
+			problems = append(problems, fmt.Sprintf("service %s image changed from %s to %s", name, locked.Image, project.Services[name].Image))
+		}
+	}
+
+	var removed []string
+	for name := range l.Services {
+		if !lockable[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		problems = append(problems, fmt.Sprintf("service %s is locked but no longer uses a pulled image", name))
+	}
+	return problems
+}
+
+// Pin 將專案中已鎖定服務的映像檔替換為 digest 參照
+func (l LockFile) Pin(project RoverCompose) RoverCompose {
+	pinned := project
+	pinned.Services = make(map[string]Service, len(project.Services))
+	for name, service := range project.Services {
+		if locked, ok := l.Services[name]; ok && service.Build == nil && locked.Image == service.Image {
+			service.Image = PinnedImage(service.Image, locked.Digest)
+		}
+		pinned.Services[name] = service
+	}
+	return pinned
+}
+
+// PinnedImage 以 digest 取代映像檔的 tag，例如 nginx:latest 轉為 nginx@sha256:...
+func PinnedImage(image, digest string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	// tag 在最後一個 / 之後；registry 的 port 不算 tag
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image + "@" + digest
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testDigest = "sha256:4c0fdaa8b6341bfdeca5f18f7837462c80cff90527ee35ef185571e1c327beac"
+
+func TestPinnedImage(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "nginx", want: "nginx@" + testDigest},
+		{image: "nginx:latest", want: "nginx@" + testDigest},
+		{image: "registry:5000/app", want: "registry:5000/app@" + testDigest},
+		{image: "registry:5000/app:1.2", want: "registry:5000/app@" + testDigest},
+		{image: "docker.io/library/nginx:1.25@sha256:0000", want: "docker.io/library/nginx@" + testDigest},
+		{image: "nginx@sha256:0000", want: "nginx@" + testDigest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := PinnedImage(tt.image, testDigest); got != tt.want {
+				t.Errorf("PinnedImage(%q) = %q, want %q", tt.image, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockFileStale(t *testing.T) {
+	project := RoverCompose{Services: map[string]Service{
+		"web":   {Image: "nginx:1.25"},
+		"db":    {Image: "postgres:16"},
+		"app":   {Image: "demo/app", Build: &BuildConfig{Context: "."}},
+		"cache": {Image: "redis:7"},
+	}}
+
+	tests := []struct {
+		name     string
+		services map[string]LockedImage
+		want     []string
+	}{
+		{
+			name: "up to date",
+			services: map[string]LockedImage{
+				"web":   {Image: "nginx:1.25", Digest: testDigest},
+				"db":    {Image: "postgres:16", Digest: testDigest},
+				"cache": {Image: "redis:7", Digest: testDigest},
+			},
+		},
+		{
+			name: "unlocked service",
+			services: map[string]LockedImage{
+				"web": {Image: "nginx:1.25", Digest: testDigest},
+				"db":  {Image: "postgres:16", Digest: testDigest},
+			},
+			want: []string{"service cache is not locked"},
+		},
+		{
+			name: "changed image",
+			services: map[string]LockedImage{
+				"web":   {Image: "nginx:1.24", Digest: testDigest},
+				"db":    {Image: "postgres:16", Digest: testDigest},
+				"cache": {Image: "redis:7", Digest: testDigest},
+			},
+			want: []string{"service web image changed from nginx:1.24 to nginx:1.25"},
+		},
+		{
+			name: "removed service and build service",
+			services: map[string]LockedImage{
+				"web":    {Image: "nginx:1.25", Digest: testDigest},
+				"db":     {Image: "postgres:16", Digest: testDigest},
+				"cache":  {Image: "redis:7", Digest: testDigest},
+				"app":    {Image: "demo/app", Digest: testDigest},
+				"worker": {Image: "demo/worker", Digest: testDigest},
+			},
+			want: []string{
+				"service app is locked but no longer uses a pulled image",
+				"service worker is locked but no longer uses a pulled image",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lock := NewLockFile()
+			lock.Services = tt.services
+			if got := lock.Stale(project); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Stale() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockFilePin(t *testing.T) {
+	project := RoverCompose{Services: map[string]Service{
+		"web":      {Image: "nginx:1.25"},
+		"changed":  {Image: "postgres:17"},
+		"unlocked": {Image: "redis:7"},
+		"app":      {Image: "demo/app", Build: &BuildConfig{Context: "."}},
+	}}
+	lock := NewLockFile()
+	lock.Services = map[string]LockedImage{
+		"web":     {Image: "nginx:1.25", Digest: testDigest},
+		"changed": {Image: "postgres:16", Digest: testDigest},
+		"app":     {Image: "demo/app", Digest: testDigest},
+		"removed": {Image: "busybox", Digest: testDigest},
+	}
+
+	pinned := lock.Pin(project)
+	want := map[string]string{
+		"web":      "nginx@" + testDigest,
+		"changed":  "postgres:17",
+		"unlocked": "redis:7",
+		"app":      "demo/app",
+	}
+	if len(pinned.Services) != len(want) {
+		t.Fatalf("Pin() returned services %v, want %v", pinned.Services, want)
+	}
+	for name, image := range want {
+		if got := pinned.Services[name].Image; got != image {
+			t.Errorf("service %s image = %q, want %q", name, got, image)
+		}
+	}
+	if project.Services["web"].Image != "nginx:1.25" {
+		t.Errorf("Pin() modified the original project: %q", project.Services["web"].Image)
+	}
+}
@@ -4,28 +4,168 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/compose-spec/compose-go/loader"
 )
 
+// ConfigFileNames 自動尋找設定檔時接受的檔名
+var ConfigFileNames = []string{
+	"compose.yaml",
+	"compose.yml",
+	"docker-compose.yaml",
+	"docker-compose.yml",
+	"rover-compose.yaml",
+	"rover-compose.yml",
+	"rover-compose.toml",
+	"rover-compose.json",
+}
+
+// LoadConfig 從目前目錄往上層尋找設定檔並解析
 func LoadConfig() (RoverCompose, error) {
 	var config RoverCompose
-	var err error
 
-	if fileExists("rover-compose.yaml") {
-		config, err = ParseYAML("rover-compose.yaml")
-	} else if fileExists("rover-compose.toml") {
-		config, err = ParseTOML("rover-compose.toml")
-	} else if fileExists("rover-compose.json") {
-		config, err = ParseJSON("rover-compose.json")
-	} else {
-		return config, errors.New("No rover-compose file found")
+	dir, err := os.Getwd()
+	if err != nil {
+		return config, err
 	}
 
+	filePath, err := FindConfigFile(dir)
+	if err != nil {
+		return config, err
+	}
+
+	config, err = LoadFile(filePath)
 	if err != nil {
 		return config, fmt.Errorf("Failed to parse config: %v", err)
 	}
 	return config, nil
 }
 
+// FindConfigFile 從 dir 開始往上層目錄尋找設定檔；同一目錄有多個候選檔案時回傳錯誤
+func FindConfigFile(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		var found []string
+		for _, name := range ConfigFileNames {
+			if fileExists(filepath.Join(dir, name)) {
+				found = append(found, name)
+			}
+		}
+
+		switch len(found) {
+		case 0:
+		case 1:
+			return filepath.Join(dir, found[0]), nil
+		default:
+			return "", fmt.Errorf("found multiple config files in %s: %s; use -f to choose one", dir, strings.Join(found, ", "))
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no config file found (looked for %s in the current directory and its parents)", strings.Join(ConfigFileNames, ", "))
+		}
+		dir = parent
+	}
+}
+
+// LoadFile 依檔名選擇解析器：rover-compose.* 使用原生格式並先以 schema 驗證，其餘 YAML 視為 docker-compose
+func LoadFile(filePath string) (RoverCompose, error) {
+	var config RoverCompose
+	var err error
+
+	base := filepath.Base(filePath)
+	// 原生格式的解析器會忽略未知欄位，先以 schema 檢查，避免拼錯的欄位被默默略過
+	if strings.HasPrefix(base, "rover-compose.") {
+		if err := validateRoverCompose(filePath); err != nil {
+			return config, err
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(base, ".toml"):
+		config, err = ParseTOML(filePath)
+	case strings.HasSuffix(base, ".json"):
+		config, err = ParseJSON(filePath)
+	case strings.HasPrefix(base, "rover-compose."):
+		config, err = ParseYAML(filePath)
+	default:
+		config, err = ParseCompose(filePath)
+	}
+	if err != nil {
+		return config, err
+	}
+
+	// 專案的工作目錄為設定檔所在目錄
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return config, err
+	}
+	config.WorkingDir = filepath.Dir(absPath)
+
+	// 未指定 name 時，以工作目錄名稱作為專案名稱
+	if config.Name == "" {
+		config.Name = loader.NormalizeProjectName(filepath.Base(config.WorkingDir))
+	}
+
+	// 相對路徑保持設定檔中的原樣，讓 convert / config 的輸出與設定雜湊不依賴專案所在位置；
+	// 呼叫 podman 或讀取檔案時才透過 HostPath、HostVolume 與 BuildContext 轉換
+	return config, nil
+}
+
+// validateRoverCompose 以 rover-compose 的 JSON Schema 驗證設定檔，所有問題合併為一個錯誤
+func validateRoverCompose(filePath string) error {
+	problems, err := ValidateFile(filePath)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, len(problems))
+	for i, problem := range problems {
+		errs[i] = problem
+	}
+	return errors.Join(errs...)
+}
+
+// HostPath 將設定檔中的相對路徑轉換為以工作目錄為基準的絕對路徑；~/ 開頭的路徑展開為使用者家目錄
+func (c RoverCompose) HostPath(p string) string {
+	return resolvePath(c.WorkingDir, p)
+}
+
+// HostVolume 回傳 podman 使用的 volume 參數，相對路徑的 bind mount 來源轉換為絕對路徑
+func (c RoverCompose) HostVolume(volume string) string {
+	return resolveVolumePath(c.WorkingDir, volume)
+}
+
+// BuildContext 回傳 podman build 使用的 context，相對路徑轉換為絕對路徑
+func (c RoverCompose) BuildContext(build *BuildConfig) string {
+	return resolveBuildContext(c.WorkingDir, build.Context)
+}
+
+// resolveVolumePath 將 ./、../ 或 ~/ 開頭的 volume 來源轉換為絕對路徑，具名 volume 維持原樣
+func resolveVolumePath(workingDir, volume string) string {
+	source, rest, found := strings.Cut(volume, ":")
+	if !found || !(strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") || source == "." || strings.HasPrefix(source, "~/")) {
+		return volume
+	}
+	return resolvePath(workingDir, source) + ":" + rest
+}
+
+// resolveBuildContext 將相對路徑的 build context 轉換為絕對路徑，遠端 context 維持原樣
+func resolveBuildContext(workingDir, context string) string {
+	if context == "" {
+		context = "."
+	}
+	if filepath.IsAbs(context) || strings.Contains(context, "://") || strings.HasPrefix(context, "git@") {
+		return context
+	}
+	return filepath.Join(workingDir, context)
+}
+
 func fileExists(filename string) bool {
 	_, err := os.Stat(filename)
 	return !os.IsNotExist(err)
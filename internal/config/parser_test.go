@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindConfigFileWalksUp(t *testing.T) {
+	root := t.TempDir()
+	want := writeFile(t, root, "rover-compose.yaml", "services:\n  web:\n    image: nginx\n")
+	nested := filepath.Join(root, "src", "app")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FindConfigFile(nested)
+	if err != nil {
+		t.Fatalf("FindConfigFile() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("FindConfigFile() = %s, want %s", got, want)
+	}
+
+	// 較近的目錄中的設定檔優先
+	closer := writeFile(t, filepath.Join(root, "src"), "compose.yaml", "services: {}\n")
+	if got, _ := FindConfigFile(nested); got != closer {
+		t.Errorf("FindConfigFile() = %s, want the closer %s", got, closer)
+	}
+}
+
+func TestFindConfigFileMultipleCandidates(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "compose.yaml", "services: {}\n")
+	writeFile(t, dir, "rover-compose.toml", "")
+
+	_, err := FindConfigFile(dir)
+	if err == nil {
+		t.Fatal("FindConfigFile() succeeded with two candidates")
+	}
+	if !strings.Contains(err.Error(), "compose.yaml, rover-compose.toml") {
+		t.Errorf("FindConfigFile() error = %v, want both candidates listed", err)
+	}
+}
+
+func TestFindConfigFileNotFound(t *testing.T) {
+	if _, err := FindConfigFile(t.TempDir()); err == nil || !strings.Contains(err.Error(), "no config file found") {
+		t.Errorf("FindConfigFile() error = %v, want no config file found", err)
+	}
+}
+
+func TestLoadFileSetsWorkingDirAndName(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "My App")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "rover-compose.yaml", "services:\n  web:\n    image: nginx\n")
+	nested := filepath.Join(dir, "sub")
+	if err := os.Mkdir(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := FindConfigFile(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if project.WorkingDir != dir {
+		t.Errorf("WorkingDir = %s, want %s", project.WorkingDir, dir)
+	}
+	if project.Name != "myapp" {
+		t.Errorf("Name = %q, want %q", project.Name, "myapp")
+	}
+
+	named := writeFile(t, t.TempDir(), "rover-compose.yaml", "name: custom\nservices: {}\n")
+	if project, err := LoadFile(named); err != nil || project.Name != "custom" {
+		t.Errorf("LoadFile() = %q, %v; want the name from the file", project.Name, err)
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ActiveProfiles 回傳啟用的 profiles：優先使用 --profile，否則讀取 COMPOSE_PROFILES
+func ActiveProfiles(flags []string) []string {
+	var profiles []string
+	if len(flags) > 0 {
+		profiles = flags
+	} else {
+		profiles = strings.Split(os.Getenv("COMPOSE_PROFILES"), ",")
+	}
+
+	active := []string{}
+	seen := make(map[string]bool)
+	for _, p := range profiles {
+		p = strings.TrimSpace(p)
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		active = append(active, p)
+	}
+	sort.Strings(active)
+	return active
+}
+
+// ProfileEnabled 判斷帶有 serviceProfiles 的服務在 active profiles 下是否啟用
+func ProfileEnabled(serviceProfiles, active []string) bool {
+	if len(serviceProfiles) == 0 {
+		return true
+	}
+	for _, a := range active {
+		if a == "*" {
+			return true
+		}
+		for _, p := range serviceProfiles {
+			if p == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ApplyProfiles 依照 active profiles 過濾服務。
+// 明確指定的 targets 不論 profile 一律啟用，其 depends_on 也會一併啟用。
+func (c RoverCompose) ApplyProfiles(active []string, targets []string) (RoverCompose, error) {
+	enabled := make(map[string]bool)
+	for name, service := range c.Services {
+		if ProfileEnabled(service.Profiles, active) {
+			enabled[name] = true
+		}
+	}
+
+	// 啟用指定服務及其所有依賴
+	visited := make(map[string]bool)
+	var activate func(name string) error
+	activate = func(name string) error {
+		service, exists := c.Services[name]
+		if !exists {
+			return fmt.Errorf("no such service: %s", name)
+		}
+		if visited[name] {
+			return nil
+		}
+		visited[name] = true
+		enabled[name] = true
+		for _, dep := range service.DependencyNames() {
+			if _, exists := c.Services[dep]; !exists && !service.Requires(dep) {
+				continue
+			}
+			if err := activate(dep); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, target := range targets {
+		if err := activate(target); err != nil {
+			return c, err
+		}
+	}
+
+	filtered := c
+	filtered.Services = make(map[string]Service, len(enabled))
+	for name := range enabled {
+		service := c.Services[name]
+		for _, dep := range service.DependencyNames() {
+			if _, exists := c.Services[dep]; exists && !enabled[dep] && service.Requires(dep) {
+				return c, fmt.Errorf("service %s depends on %s, which is not enabled by the active profiles", name, dep)
+			}
+		}
+		filtered.Services[name] = service
+	}
+
+	return filtered, nil
+}
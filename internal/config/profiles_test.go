@@ -0,0 +1,129 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// profileProject web 沒有 profile；debugger 與 admin 分屬不同 profile，admin 依賴同樣在 profile 中的 metrics
+func profileProject() RoverCompose {
+	return RoverCompose{Services: map[string]Service{
+		"web":      {Image: "nginx", DependsOn: DependsOn{"db": defaultDependency()}},
+		"db":       {Image: "postgres"},
+		"debugger": {Image: "busybox", Profiles: []string{"debug"}},
+		"admin":    {Image: "admin", Profiles: []string{"tools"}, DependsOn: DependsOn{"metrics": defaultDependency()}},
+		"metrics":  {Image: "prometheus", Profiles: []string{"monitoring"}},
+	}}
+}
+
+func serviceNames(project RoverCompose) []string {
+	names := make([]string, 0, len(project.Services))
+	for name := range project.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestActiveProfiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags []string
+		env   string
+		want  []string
+	}{
+		{name: "none", want: []string{}},
+		{name: "environment", env: "debug, tools,,debug", want: []string{"debug", "tools"}},
+		{name: "flags override environment", flags: []string{"tools", "debug", "tools"}, env: "monitoring", want: []string{"debug", "tools"}},
+		{name: "wildcard", env: "*", want: []string{"*"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COMPOSE_PROFILES", tt.env)
+			if got := ActiveProfiles(tt.flags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ActiveProfiles(%v) = %q, want %q", tt.flags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		active  []string
+		targets []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "no profiles keeps services without profiles",
+			want: []string{"db", "web"},
+		},
+		{
+			name:   "active profile",
+			active: []string{"debug"},
+			want:   []string{"db", "debugger", "web"},
+		},
+		{
+			name:   "wildcard enables every profile",
+			active: []string{"*"},
+			want:   []string{"admin", "db", "debugger", "metrics", "web"},
+		},
+		{
+			name:    "targeted service activates its profile",
+			targets: []string{"debugger"},
+			want:    []string{"db", "debugger", "web"},
+		},
+		{
+			name:    "targeted service activates its dependencies",
+			targets: []string{"admin"},
+			want:    []string{"admin", "db", "metrics", "web"},
+		},
+		{
+			name:    "dependency outside the active profiles",
+			active:  []string{"tools"},
+			wantErr: "service admin depends on metrics, which is not enabled by the active profiles",
+		},
+		{
+			name:    "unknown target",
+			targets: []string{"missing"},
+			wantErr: "no such service: missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := profileProject().ApplyProfiles(tt.active, tt.targets)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ApplyProfiles() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyProfiles() error = %v", err)
+			}
+			if names := serviceNames(got); !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("ApplyProfiles() services = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyProfilesOptionalDependency(t *testing.T) {
+	project := RoverCompose{Services: map[string]Service{
+		"web":   {Image: "nginx", DependsOn: DependsOn{"cache": {Condition: ServiceConditionStarted, Required: false}}},
+		"cache": {Image: "redis", Profiles: []string{"cache"}},
+	}}
+
+	got, err := project.ApplyProfiles(nil, nil)
+	if err != nil {
+		t.Fatalf("ApplyProfiles() error = %v", err)
+	}
+	if names := serviceNames(got); !reflect.DeepEqual(names, []string{"web"}) {
+		t.Errorf("ApplyProfiles() services = %v, want [web]", names)
+	}
+}
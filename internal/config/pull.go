@@ -0,0 +1,22 @@
+package config
+
+// pull_policy 支援的值
+const (
+	PullPolicyAlways       = "always"
+	PullPolicyMissing      = "missing"
+	PullPolicyNever        = "never"
+	PullPolicyBuild        = "build"
+	PullPolicyIfNotPresent = "if_not_present" // missing 的別名
+)
+
+// PullPolicies 所有可用的 pull_policy
+var PullPolicies = []string{PullPolicyAlways, PullPolicyMissing, PullPolicyNever, PullPolicyBuild, PullPolicyIfNotPresent}
+
+// EffectivePullPolicy 回傳服務實際使用的 pull_policy；未設定時為 missing
+func (s Service) EffectivePullPolicy() string {
+	switch s.PullPolicy {
+	case "", PullPolicyIfNotPresent:
+		return PullPolicyMissing
+	}
+	return s.PullPolicy
+}
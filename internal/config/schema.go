@@ -0,0 +1,116 @@
+package config
+
+//go:generate sh -c "cd ../.. && go run . validate --print-schema > schema/rover-compose.schema.json"
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaID rover-compose JSON Schema 的發佈位置
+const SchemaID = "https://github.com/vvvdwbvvv/rover/blob/main/schema/rover-compose.schema.json"
+
+// schemaEnums 限定欄位可用的值，key 為「型別.欄位」
+var schemaEnums = map[string][]string{
+	"Service.PullPolicy":  PullPolicies,
+	"SecretProvider.Type": SecretProviderTypes,
+}
+
+// GenerateSchema 以 RoverCompose 的型別定義產生 rover-compose 的 JSON Schema
+func GenerateSchema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeOf(RoverCompose{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["$id"] = SchemaID
+	schema["title"] = "rover-compose"
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// typeSchema 依照 json tag 將 Go 型別轉換為 JSON Schema
+func typeSchema(t reflect.Type) map[string]interface{} {
+	switch t {
+	case reflect.TypeOf(DependsOn{}):
+		return dependsOnSchema()
+	case reflect.TypeOf(BuildConfig{}):
+		// build 可為 context 路徑字串或完整設定
+		return map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				typeSchema(reflect.TypeOf(buildConfig{})),
+			},
+		}
+	case reflect.TypeOf(FileReference{}):
+		// secrets / configs 可為來源名稱字串或完整設定
+		return map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				typeSchema(reflect.TypeOf(fileReference{})),
+			},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = typeSchema(field.Type)
+			if values, ok := schemaEnums[t.Name()+"."+field.Name]; ok {
+				properties[name].(map[string]interface{})["enum"] = values
+			}
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"patternProperties":    map[string]interface{}{"^x-": map[string]interface{}{}},
+			"additionalProperties": false,
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// dependsOnSchema depends_on 可為服務名稱列表或長格式 mapping
+func dependsOnSchema() map[string]interface{} {
+	dependency := typeSchema(reflect.TypeOf(ServiceDependency{}))
+	dependency["properties"].(map[string]interface{})["condition"] = map[string]interface{}{
+		"type": "string",
+		"enum": []string{ServiceConditionStarted, ServiceConditionHealthy, ServiceConditionCompletedSuccessfully},
+	}
+
+	return map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"uniqueItems": true,
+			},
+			map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": dependency,
+			},
+		},
+	}
+}
@@ -0,0 +1,251 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/vvvdwbvvv/rover/internal/secrets"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileObject 定義頂層 secrets / configs 的內容來源，file、environment、content、provider 擇一
+type FileObject struct {
+	File        string `yaml:"file,omitempty" toml:"file,omitempty" json:"file,omitempty"`
+	Environment string `yaml:"environment,omitempty" toml:"environment,omitempty" json:"environment,omitempty"`
+	Content     string `yaml:"content,omitempty" toml:"content,omitempty" json:"content,omitempty"`
+	Provider    string `yaml:"provider,omitempty" toml:"provider,omitempty" json:"provider,omitempty"`
+	Key         string `yaml:"key,omitempty" toml:"key,omitempty" json:"key,omitempty"` // provider 中的名稱，預設為 secret 名稱
+}
+
+// Value 讀取內容；file 的相對路徑以 workingDir 為基準。使用 provider 的內容由 SecretResolver 讀取
+func (o FileObject) Value(workingDir string) ([]byte, error) {
+	switch {
+	case o.File != "":
+		return os.ReadFile(resolvePath(workingDir, o.File))
+	case o.Environment != "":
+		value, ok := os.LookupEnv(o.Environment)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", o.Environment)
+		}
+		return []byte(value), nil
+	case o.Content != "":
+		return []byte(o.Content), nil
+	}
+	return nil, fmt.Errorf("no file, environment or content set")
+}
+
+// secret provider 的類型
+const (
+	SecretProviderEncryptedFile = "encrypted_file"
+	SecretProviderEnvironment   = "environment"
+	SecretProviderCommand       = "command"
+)
+
+// SecretProviderTypes 所有可用的 provider 類型
+var SecretProviderTypes = []string{SecretProviderEncryptedFile, SecretProviderEnvironment, SecretProviderCommand}
+
+// SecretProvider 定義 secrets 在 apply 時的讀取來源
+type SecretProvider struct {
+	Type          string   `yaml:"type" toml:"type" json:"type"`
+	File          string   `yaml:"file,omitempty" toml:"file,omitempty" json:"file,omitempty"`                               // encrypted_file：加密檔路徑
+	KeyFile       string   `yaml:"key_file,omitempty" toml:"key_file,omitempty" json:"key_file,omitempty"`                   // encrypted_file：金鑰檔路徑
+	PassphraseEnv string   `yaml:"passphrase_env,omitempty" toml:"passphrase_env,omitempty" json:"passphrase_env,omitempty"` // encrypted_file：密語的環境變數
+	Command       []string `yaml:"command,omitempty" toml:"command,omitempty" json:"command,omitempty"`                      // command：外部指令
+	Prefix        string   `yaml:"prefix,omitempty" toml:"prefix,omitempty" json:"prefix,omitempty"`                         // environment：變數名稱前綴
+}
+
+// FileReference 服務掛載的 secret / config；短格式為來源名稱
+type FileReference struct {
+	Source string  `yaml:"source" toml:"source" json:"source"`
+	Target string  `yaml:"target,omitempty" toml:"target,omitempty" json:"target,omitempty"`
+	UID    string  `yaml:"uid,omitempty" toml:"uid,omitempty" json:"uid,omitempty"`
+	GID    string  `yaml:"gid,omitempty" toml:"gid,omitempty" json:"gid,omitempty"`
+	Mode   *uint32 `yaml:"mode,omitempty" toml:"mode,omitempty" json:"mode,omitempty"`
+}
+
+// fileReference 避免 Unmarshal 遞迴呼叫自身
+type fileReference FileReference
+
+func (r *FileReference) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*r = FileReference{Source: value.Value}
+		return nil
+	}
+	return value.Decode((*fileReference)(r))
+}
+
+func (r *FileReference) UnmarshalJSON(data []byte) error {
+	var source string
+	if err := json.Unmarshal(data, &source); err == nil {
+		*r = FileReference{Source: source}
+		return nil
+	}
+	return json.Unmarshal(data, (*fileReference)(r))
+}
+
+func (r *FileReference) UnmarshalTOML(raw interface{}) error {
+	if source, ok := raw.(string); ok {
+		*r = FileReference{Source: source}
+		return nil
+	}
+	// TOML 與 JSON 的欄位名稱相同，透過 JSON 轉換到結構
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, (*fileReference)(r))
+}
+
+// SecretMountPath 回傳 secret 在容器內的路徑；預設為 /run/secrets/<source>，相對路徑的 target 也放在 /run/secrets 下
+func (r FileReference) SecretMountPath() string {
+	target := r.Target
+	if target == "" {
+		target = r.Source
+	}
+	if path.IsAbs(target) {
+		return target
+	}
+	return path.Join("/run/secrets", target)
+}
+
+// ConfigMountPath 回傳 config 在容器內的路徑；預設為 /<source>
+func (r FileReference) ConfigMountPath() string {
+	target := r.Target
+	if target == "" {
+		target = r.Source
+	}
+	return path.Join("/", target)
+}
+
+// SecretResolver 讀取頂層 secrets / configs 的內容；同一個 provider 只開啟一次，避免重複詢問密語
+type SecretResolver struct {
+	project   RoverCompose
+	providers map[string]secrets.Provider
+}
+
+// NewSecretResolver 建立專案的 SecretResolver
+func NewSecretResolver(project RoverCompose) *SecretResolver {
+	return &SecretResolver{project: project, providers: make(map[string]secrets.Provider)}
+}
+
+// Secret 讀取頂層 secret 的內容
+func (r *SecretResolver) Secret(name string) ([]byte, error) {
+	secret, ok := r.project.Secrets[name]
+	if !ok {
+		return nil, fmt.Errorf("secret %s is not defined", name)
+	}
+	if secret.Content != "" {
+		return nil, fmt.Errorf("secret %s: content is only supported for configs", name)
+	}
+	value, err := r.value(name, secret)
+	if err != nil {
+		return nil, fmt.Errorf("secret %s: %v", name, err)
+	}
+	return value, nil
+}
+
+// Config 讀取頂層 config 的內容
+func (r *SecretResolver) Config(name string) ([]byte, error) {
+	object, ok := r.project.Configs[name]
+	if !ok {
+		return nil, fmt.Errorf("config %s is not defined", name)
+	}
+	value, err := r.value(name, object)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %v", name, err)
+	}
+	return value, nil
+}
+
+func (r *SecretResolver) value(name string, object FileObject) ([]byte, error) {
+	if object.Provider == "" {
+		return object.Value(r.project.WorkingDir)
+	}
+
+	provider, err := r.Provider(object.Provider)
+	if err != nil {
+		return nil, err
+	}
+	key := object.Key
+	if key == "" {
+		key = name
+	}
+	return provider.Lookup(key)
+}
+
+// Provider 開啟 secret_providers 中定義的 provider
+func (r *SecretResolver) Provider(name string) (secrets.Provider, error) {
+	if provider, ok := r.providers[name]; ok {
+		return provider, nil
+	}
+	definition, ok := r.project.SecretProviders[name]
+	if !ok {
+		return nil, fmt.Errorf("secret provider %s is not defined", name)
+	}
+
+	var provider secrets.Provider
+	switch definition.Type {
+	case SecretProviderEnvironment:
+		provider = secrets.Environment{Prefix: definition.Prefix}
+	case SecretProviderCommand:
+		provider = secrets.Command{Args: definition.Command, Dir: r.project.WorkingDir}
+	case SecretProviderEncryptedFile:
+		store, err := r.project.OpenSecretStore(name, false)
+		if err != nil {
+			return nil, err
+		}
+		provider = store
+	default:
+		return nil, fmt.Errorf("secret provider %s: unsupported type %q", name, definition.Type)
+	}
+	r.providers[name] = provider
+	return provider, nil
+}
+
+// OpenSecretStore 開啟 encrypted_file 類型的 provider；未指定 file 時使用使用者設定目錄下的預設路徑。
+// confirmNew 為 true 且加密檔尚不存在時，在終端機上輸入的密語需輸入兩次確認
+func (c RoverCompose) OpenSecretStore(name string, confirmNew bool) (*secrets.Store, error) {
+	definition, ok := c.SecretProviders[name]
+	if !ok {
+		return nil, fmt.Errorf("secret provider %s is not defined", name)
+	}
+	if definition.Type != SecretProviderEncryptedFile {
+		return nil, fmt.Errorf("secret provider %s is not an %s provider", name, SecretProviderEncryptedFile)
+	}
+
+	path := c.HostPath(definition.File)
+	if path == "" {
+		var err error
+		if path, err = secrets.DefaultStorePath(c.Name); err != nil {
+			return nil, err
+		}
+	}
+	confirm := false
+	if confirmNew {
+		_, err := os.Stat(path)
+		confirm = os.IsNotExist(err)
+	}
+	material, err := secrets.ReadKeyMaterial(c.HostPath(definition.KeyFile), definition.PassphraseEnv, confirm)
+	if err != nil {
+		return nil, fmt.Errorf("secret provider %s: %v", name, err)
+	}
+	return secrets.OpenStore(path, material)
+}
+
+// resolvePath 將相對路徑轉換為以工作目錄為基準的絕對路徑；~/ 開頭的路徑展開為使用者家目錄
+func resolvePath(workingDir, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return filepath.Join(workingDir, p)
+}
@@ -0,0 +1,47 @@
+package config
+
+import "fmt"
+
+// WithServices 只保留指定的服務；includeDeps 為 true 時一併保留其遞移的 depends_on
+func (c RoverCompose) WithServices(names []string, includeDeps bool) (RoverCompose, error) {
+	if len(names) == 0 {
+		return c, nil
+	}
+
+	selected := make(map[string]bool)
+	var visit func(name string) error
+	visit = func(name string) error {
+		service, exists := c.Services[name]
+		if !exists {
+			return fmt.Errorf("no such service: %s", name)
+		}
+		if selected[name] {
+			return nil
+		}
+		selected[name] = true
+		if !includeDeps {
+			return nil
+		}
+		for _, dep := range service.DependencyNames() {
+			if _, exists := c.Services[dep]; !exists && !service.Requires(dep) {
+				continue
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return c, err
+		}
+	}
+
+	subset := c
+	subset.Services = make(map[string]Service, len(selected))
+	for name := range selected {
+		subset.Services[name] = c.Services[name]
+	}
+	return subset, nil
+}
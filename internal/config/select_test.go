@@ -0,0 +1,43 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithServices(t *testing.T) {
+	project := RoverCompose{Services: map[string]Service{
+		"web":    {Image: "nginx", DependsOn: DependsOn{"api": defaultDependency()}},
+		"api":    {Image: "api", DependsOn: DependsOn{"db": defaultDependency(), "cache": {Condition: ServiceConditionStarted, Required: false}}},
+		"db":     {Image: "postgres"},
+		"worker": {Image: "worker", DependsOn: DependsOn{"db": defaultDependency()}},
+	}}
+
+	tests := []struct {
+		name        string
+		names       []string
+		includeDeps bool
+		want        []string
+	}{
+		{name: "no names keeps every service", includeDeps: true, want: []string{"api", "db", "web", "worker"}},
+		{name: "transitive dependencies", names: []string{"web"}, includeDeps: true, want: []string{"api", "db", "web"}},
+		{name: "no deps", names: []string{"web"}, want: []string{"web"}},
+		{name: "several targets", names: []string{"worker", "api"}, want: []string{"api", "worker"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := project.WithServices(tt.names, tt.includeDeps)
+			if err != nil {
+				t.Fatalf("WithServices() error = %v", err)
+			}
+			if names := serviceNames(got); !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("WithServices(%v, %v) = %v, want %v", tt.names, tt.includeDeps, names, tt.want)
+			}
+		})
+	}
+
+	if _, err := project.WithServices([]string{"web", "missing"}, true); err == nil || err.Error() != "no such service: missing" {
+		t.Errorf("WithServices() error = %v, want no such service: missing", err)
+	}
+}
@@ -2,18 +2,35 @@ package config
 
 type Service struct {
 	Name        string            `yaml:"name" toml:"name" json:"name"`
-	Image       string            `yaml:"image" toml:"image" json:"image"`
+	Image       string            `yaml:"image,omitempty" toml:"image,omitempty" json:"image,omitempty"`
+	Build       *BuildConfig      `yaml:"build,omitempty" toml:"build,omitempty" json:"build,omitempty"`
+	PullPolicy  string            `yaml:"pull_policy,omitempty" toml:"pull_policy,omitempty" json:"pull_policy,omitempty"`
 	Command     []string          `yaml:"command,omitempty" toml:"command,omitempty" json:"command,omitempty"`
 	Ports       []string          `yaml:"ports,omitempty" toml:"ports,omitempty" json:"ports,omitempty"`
 	Volumes     []string          `yaml:"volumes,omitempty" toml:"volumes,omitempty" json:"volumes,omitempty"`
 	Environment map[string]string `yaml:"environment,omitempty" toml:"environment,omitempty" json:"environment,omitempty"`
-	DependsOn   []string          `yaml:"depends_on,omitempty" toml:"depends_on,omitempty" json:"depends_on,omitempty"`
+	DependsOn   DependsOn         `yaml:"depends_on,omitempty" toml:"depends_on,omitempty" json:"depends_on,omitempty"`
+	NetworkMode string            `yaml:"network_mode,omitempty" toml:"network_mode,omitempty" json:"network_mode,omitempty"`
+	Links       []string          `yaml:"links,omitempty" toml:"links,omitempty" json:"links,omitempty"`
+	VolumesFrom []string          `yaml:"volumes_from,omitempty" toml:"volumes_from,omitempty" json:"volumes_from,omitempty"`
+	Restart     string            `yaml:"restart,omitempty" toml:"restart,omitempty" json:"restart,omitempty"`
+	Profiles    []string          `yaml:"profiles,omitempty" toml:"profiles,omitempty" json:"profiles,omitempty"`
+	Secrets     []FileReference   `yaml:"secrets,omitempty" toml:"secrets,omitempty" json:"secrets,omitempty"`
+	Configs     []FileReference   `yaml:"configs,omitempty" toml:"configs,omitempty" json:"configs,omitempty"`
 }
 
 type RoverCompose struct {
+	// WorkingDir 設定檔所在目錄，由 LoadFile 設定，不會寫入設定檔
+	WorkingDir string `yaml:"-" toml:"-" json:"-"`
+
+	Name     string             `yaml:"name,omitempty" toml:"name,omitempty" json:"name,omitempty"`
 	Version  string             `yaml:"version" toml:"version" json:"version"`
 	Services map[string]Service `yaml:"services" toml:"services" json:"services"`
 	Volumes  map[string]struct {
 		Driver string `yaml:"driver,omitempty" toml:"driver,omitempty" json:"driver,omitempty"`
 	} `yaml:"volumes,omitempty" toml:"volumes,omitempty" json:"volumes,omitempty"`
+	Secrets map[string]FileObject `yaml:"secrets,omitempty" toml:"secrets,omitempty" json:"secrets,omitempty"`
+	Configs map[string]FileObject `yaml:"configs,omitempty" toml:"configs,omitempty" json:"configs,omitempty"`
+
+	SecretProviders map[string]SecretProvider `yaml:"secret_providers,omitempty" toml:"secret_providers,omitempty" json:"secret_providers,omitempty"`
 }
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	composeschema "github.com/compose-spec/compose-go/schema"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// Position 設定檔中的行列位置（從 1 開始）
+type Position struct {
+	Line   int
+	Column int
+}
+
+// ValidationError 指向設定檔中特定位置的驗證錯誤
+type ValidationError struct {
+	File    string
+	Line    int
+	Column  int
+	Field   string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
+	}
+	if e.Field != "" {
+		return fmt.Sprintf("%s: %s: %s", location, e.Field, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", location, e.Message)
+}
+
+// ValidateFile 以 JSON Schema 驗證設定檔：rover-compose.* 使用 Rover 的 schema，
+// 其餘 YAML 視為 docker-compose 並使用 compose-spec 的 schema
+func ValidateFile(filePath string) ([]ValidationError, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	base := filepath.Base(filePath)
+	var document interface{}
+	var positions map[string]Position
+	var syntaxErr error
+
+	switch {
+	case strings.HasSuffix(base, ".toml"):
+		table := map[string]interface{}{}
+		if _, syntaxErr = toml.Decode(string(data), &table); syntaxErr == nil {
+			document = table
+			positions = tomlLayout(data).positions
+		}
+	case strings.HasSuffix(base, ".json"):
+		if syntaxErr = json.Unmarshal(data, &document); syntaxErr == nil {
+			positions = jsonLayout(data).positions
+		}
+	default:
+		var node yaml.Node
+		if syntaxErr = yaml.Unmarshal(data, &node); syntaxErr == nil {
+			syntaxErr = node.Decode(&document)
+			positions = yamlLayout(&node).positions
+		}
+	}
+	if syntaxErr != nil {
+		return []ValidationError{syntaxError(filePath, data, syntaxErr)}, nil
+	}
+
+	var schema gojsonschema.JSONLoader
+	if strings.HasPrefix(base, "rover-compose.") {
+		generated, err := GenerateSchema()
+		if err != nil {
+			return nil, err
+		}
+		schema = gojsonschema.NewBytesLoader(generated)
+	} else {
+		schema = gojsonschema.NewStringLoader(composeschema.Schema)
+	}
+
+	result, err := gojsonschema.Validate(schema, gojsonschema.NewGoLoader(document))
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate %s: %w", filePath, err)
+	}
+
+	var problems []ValidationError
+	for _, e := range result.Errors() {
+		field := e.Field()
+		if field == "(root)" {
+			field = ""
+		}
+		// oneOf 的子 schema 已回報更具體的錯誤時，略過籠統的 oneOf 訊息
+		if e.Type() == "number_one_of" && hasNestedError(result.Errors(), field) {
+			continue
+		}
+		// 多出的欄位指向該欄位本身，而非其所在的物件
+		if e.Type() == "additional_property_not_allowed" {
+			if property, ok := e.Details()["property"].(string); ok {
+				field = joinPath(field, property)
+			}
+		}
+
+		position := lookupPosition(positions, field)
+		problems = append(problems, ValidationError{
+			File:    filePath,
+			Line:    position.Line,
+			Column:  position.Column,
+			Field:   field,
+			Message: e.Description(),
+		})
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+	return problems, nil
+}
+
+func hasNestedError(errs []gojsonschema.ResultError, field string) bool {
+	for _, e := range errs {
+		if strings.HasPrefix(e.Field(), field+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(parent, child string) string {
+	if parent == "" {
+		return child
+	}
+	return parent + "." + child
+}
+
+// lookupPosition 找出路徑（或其最接近的上層路徑）的位置
+func lookupPosition(positions map[string]Position, field string) Position {
+	for {
+		if position, ok := positions[field]; ok {
+			return position
+		}
+		i := strings.LastIndex(field, ".")
+		if i < 0 {
+			return positions[""]
+		}
+		field = field[:i]
+	}
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// syntaxError 將各格式的語法錯誤轉換為帶有位置的 ValidationError
+func syntaxError(filePath string, data []byte, err error) ValidationError {
+	problem := ValidationError{File: filePath, Message: err.Error()}
+
+	var tomlErr toml.ParseError
+	var jsonErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &tomlErr):
+		problem.Line, problem.Column = offsetPosition(data, tomlErr.Position.Start)
+	case errors.As(err, &jsonErr):
+		problem.Line, problem.Column = offsetPosition(data, int(jsonErr.Offset))
+	case errors.As(err, &typeErr):
+		problem.Line, problem.Column = offsetPosition(data, int(typeErr.Offset))
+	default:
+		if m := yamlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+			problem.Line, _ = strconv.Atoi(m[1])
+			problem.Column = 1
+		}
+	}
+	return problem
+}
+
+// offsetPosition 將位元組偏移量轉換為行列位置
+func offsetPosition(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	line, column := 1, 1
+	for _, b := range data[:offset] {
+		if b == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
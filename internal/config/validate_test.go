@@ -0,0 +1,125 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateFileReportsPosition(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		content    string
+		wantField  string
+		wantLine   int
+		wantColumn int
+	}{
+		{
+			name: "yaml",
+			file: "rover-compose.yaml",
+			content: `name: demo
+services:
+  web:
+    image: nginx
+    portz:
+      - "8080:80"
+`,
+			wantField:  "services.web.portz",
+			wantLine:   5,
+			wantColumn: 5,
+		},
+		{
+			name: "toml",
+			file: "rover-compose.toml",
+			content: `name = "demo"
+
+[services.web]
+image = "nginx"
+portz = ["8080:80"]
+`,
+			wantField:  "services.web.portz",
+			wantLine:   5,
+			wantColumn: 1,
+		},
+		{
+			name: "json",
+			file: "rover-compose.json",
+			content: `{
+  "name": "demo",
+  "services": {
+    "web": {
+      "image": "nginx",
+      "portz": ["8080:80"]
+    }
+  }
+}
+`,
+			wantField:  "services.web.portz",
+			wantLine:   6,
+			wantColumn: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, t.TempDir(), tt.file, tt.content)
+
+			problems, err := ValidateFile(path)
+			if err != nil {
+				t.Fatalf("ValidateFile() error = %v", err)
+			}
+			if len(problems) != 1 {
+				t.Fatalf("ValidateFile() = %v, want one problem", problems)
+			}
+			got := problems[0]
+			if got.File != path || got.Field != tt.wantField {
+				t.Errorf("problem = %s:%s, want %s:%s", got.File, got.Field, path, tt.wantField)
+			}
+			if got.Line != tt.wantLine || got.Column != tt.wantColumn {
+				t.Errorf("position = %d:%d, want %d:%d", got.Line, got.Column, tt.wantLine, tt.wantColumn)
+			}
+		})
+	}
+}
+
+func TestValidateFileSyntaxError(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "rover-compose.json", "{\n  \"name\": \"demo\",\n  \"services\": {,}\n}\n")
+
+	problems, err := ValidateFile(path)
+	if err != nil {
+		t.Fatalf("ValidateFile() error = %v", err)
+	}
+	if len(problems) != 1 || problems[0].Line != 3 {
+		t.Fatalf("ValidateFile() = %v, want one problem on line 3", problems)
+	}
+}
+
+func TestLoadFileRejectsUnknownFields(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "rover-compose.yaml", "services:\n  web:\n    imagee: nginx\n")
+
+	_, err := LoadFile(path)
+	if err == nil {
+		t.Fatal("LoadFile() accepted a mistyped key")
+	}
+	if !strings.Contains(err.Error(), path+":3:5") || !strings.Contains(err.Error(), "services.web.imagee") {
+		t.Errorf("LoadFile() error = %v, want position of services.web.imagee", err)
+	}
+}
+
+// TestSchemaUpToDate 確保發佈的 schema 與型別定義一致；不一致時執行 go generate ./internal/config
+func TestSchemaUpToDate(t *testing.T) {
+	generated, err := GenerateSchema()
+	if err != nil {
+		t.Fatalf("GenerateSchema() error = %v", err)
+	}
+	committed, err := os.ReadFile(filepath.Join("..", "..", "schema", "rover-compose.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(committed), bytes.TrimSpace(generated)) {
+		t.Error("schema/rover-compose.schema.json is out of date; run go generate ./internal/config")
+	}
+}
@@ -0,0 +1,150 @@
+package container
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vvvdwbvvv/rover/pkg/model"
+)
+
+// Inspection podman inspect 輸出中 Rover 使用的欄位
+type Inspection struct {
+	ID           string `json:"Id"`
+	Name         string `json:"Name"`
+	Created      time.Time
+	ImageID      string `json:"Image"`
+	ImageName    string `json:"ImageName"`
+	ImageDigest  string `json:"ImageDigest"`
+	RestartCount int    `json:"RestartCount"`
+	State        struct {
+		Status     string    `json:"Status"`
+		Running    bool      `json:"Running"`
+		ExitCode   int       `json:"ExitCode"`
+		StartedAt  time.Time `json:"StartedAt"`
+		FinishedAt time.Time `json:"FinishedAt"`
+		Health     *struct {
+			Status string `json:"Status"`
+		} `json:"Health,omitempty"`
+	} `json:"State"`
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	Mounts []struct {
+		Type        string `json:"Type"`
+		Source      string `json:"Source"`
+		Destination string `json:"Destination"`
+		RW          bool   `json:"RW"`
+	} `json:"Mounts"`
+	NetworkSettings struct {
+		Ports map[string][]struct {
+			HostIP   string `json:"HostIp"`
+			HostPort string `json:"HostPort"`
+		} `json:"Ports"`
+	} `json:"NetworkSettings"`
+}
+
+// ErrNoSuchContainer 執行環境中沒有該容器
+var ErrNoSuchContainer = fmt.Errorf("no such container")
+
+// Inspect 以 podman inspect 讀取容器的實際狀態
+func Inspect(name string) (*Inspection, error) {
+	cmd := exec.Command("podman", "container", "inspect", "--format", "json", name)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if strings.Contains(stderr.String(), "no such container") {
+			return nil, ErrNoSuchContainer
+		}
+		return nil, fmt.Errorf("inspect %s failed: %v: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var inspections []Inspection
+	if err := json.Unmarshal(stdout.Bytes(), &inspections); err != nil {
+		return nil, fmt.Errorf("invalid inspect output for %s: %v", name, err)
+	}
+	if len(inspections) == 0 {
+		return nil, ErrNoSuchContainer
+	}
+	return &inspections[0], nil
+}
+
+// ApplyLabels 以容器的 label 填入 Rover 設定的欄位，用於沒有記錄或需要重建記錄的容器
+func (i *Inspection) ApplyLabels(state *model.ContainerState) {
+	labels := i.Config.Labels
+	state.Project = labels[LabelProject]
+	state.Service = labels[LabelService]
+	state.ConfigHash = labels[LabelConfigHash]
+	state.Revision, _ = strconv.Atoi(labels[LabelRevision])
+	state.Profiles = nil
+	if profiles := labels[LabelProfiles]; profiles != "" {
+		state.Profiles = strings.Split(profiles, ",")
+	}
+	if state.Service == "" {
+		state.Service = state.Name
+	}
+	if state.Replica == 0 {
+		state.Replica = 1
+	}
+}
+
+// ConfigFile 回傳建立容器時使用的設定檔，沒有 label 時回傳空字串
+func (i *Inspection) ConfigFile() string {
+	return i.Config.Labels[LabelConfigFile]
+}
+
+// Apply 將實際狀態寫入 state；Project、Service、ConfigHash 等由 Rover 設定的欄位保持不變。
+// 狀態的變更不是執行環境可能造成的轉換時，仍寫入所有欄位並回傳 Observe 的錯誤
+func (i *Inspection) Apply(state *model.ContainerState) error {
+	state.ID = i.ID
+	state.Image = i.ImageName
+	state.ImageDigest = i.ImageDigest
+	health := ""
+	if i.State.Health != nil {
+		health = i.State.Health.Status
+	}
+	observeErr := state.Observe(model.StatusFromRuntime(i.State.Status, health, i.State.ExitCode), time.Now())
+	state.CreatedAt = i.Created
+	state.StartedAt = i.State.StartedAt
+	state.FinishedAt = i.State.FinishedAt
+	state.ExitCode = i.State.ExitCode
+	state.RestartCount = i.RestartCount
+
+	state.Ports = nil
+	for spec, bindings := range i.NetworkSettings.Ports {
+		port, protocol, _ := strings.Cut(spec, "/")
+		containerPort, _ := strconv.Atoi(port)
+		for _, binding := range bindings {
+			hostPort, _ := strconv.Atoi(binding.HostPort)
+			state.Ports = append(state.Ports, model.PortBinding{
+				HostIP:        binding.HostIP,
+				HostPort:      hostPort,
+				ContainerPort: containerPort,
+				Protocol:      protocol,
+			})
+		}
+	}
+	sort.Slice(state.Ports, func(a, b int) bool {
+		if state.Ports[a].ContainerPort != state.Ports[b].ContainerPort {
+			return state.Ports[a].ContainerPort < state.Ports[b].ContainerPort
+		}
+		return state.Ports[a].HostPort < state.Ports[b].HostPort
+	})
+
+	state.Mounts = nil
+	for _, m := range i.Mounts {
+		state.Mounts = append(state.Mounts, model.Mount{
+			Type:        m.Type,
+			Source:      m.Source,
+			Destination: m.Destination,
+			ReadOnly:    !m.RW,
+		})
+	}
+	return observeErr
+}
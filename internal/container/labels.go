@@ -0,0 +1,77 @@
+package container
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/vvvdwbvvv/rover/pkg/model"
+)
+
+// Rover 加在容器上的 label，用來從執行環境辨識容器所屬的專案與服務，並在狀態遺失時重建
+const (
+	LabelProject    = "io.rover.project"
+	LabelService    = "io.rover.service"
+	LabelConfigHash = "io.rover.config-hash"
+	LabelRevision   = "io.rover.revision"
+	LabelProfiles   = "io.rover.profiles"    // 以逗號分隔
+	LabelConfigFile = "io.rover.config-file" // apply 使用的設定檔絕對路徑
+)
+
+// Labels 依容器記錄產生 Rover 設定的 label；ApplyLabels 為其反向操作
+func Labels(state model.ContainerState, configFile string) map[string]string {
+	labels := map[string]string{
+		LabelProject: state.Project,
+		LabelService: state.Service,
+	}
+	if state.ConfigHash != "" {
+		labels[LabelConfigHash] = state.ConfigHash
+	}
+	if state.Revision > 0 {
+		labels[LabelRevision] = strconv.Itoa(state.Revision)
+	}
+	if len(state.Profiles) > 0 {
+		labels[LabelProfiles] = strings.Join(state.Profiles, ",")
+	}
+	if configFile != "" {
+		labels[LabelConfigFile] = configFile
+	}
+	return labels
+}
+
+// Summary podman ps 輸出中 Rover 使用的欄位
+type Summary struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Image  string            `json:"Image"`
+	State  string            `json:"State"`
+	Labels map[string]string `json:"Labels"`
+}
+
+// Name 回傳容器名稱
+func (s Summary) Name() string {
+	if len(s.Names) == 0 {
+		return ""
+	}
+	return s.Names[0]
+}
+
+// ListManaged 列出執行環境中帶有 Rover 專案 label 的所有容器（包含已停止的）
+func ListManaged() ([]Summary, error) {
+	cmd := exec.Command("podman", "ps", "-a", "--filter", "label="+LabelProject, "--format", "json")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("list containers failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var summaries []Summary
+	if err := json.Unmarshal(stdout.Bytes(), &summaries); err != nil {
+		return nil, fmt.Errorf("invalid podman ps output: %v", err)
+	}
+	return summaries, nil
+}
@@ -0,0 +1,79 @@
+package container
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vvvdwbvvv/rover/pkg/model"
+)
+
+// TestLabelsRoundTrip state rebuild 以 ApplyLabels 讀回 apply 時由 Labels 寫入的欄位
+func TestLabelsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		state      model.ContainerState
+		configFile string
+	}{
+		{
+			name: "all fields",
+			state: model.ContainerState{
+				Name:       "demo-web",
+				Project:    "demo",
+				Service:    "web",
+				Replica:    1,
+				ConfigHash: "3f2a9c",
+				Revision:   12,
+				Profiles:   []string{"debug", "tools"},
+			},
+			configFile: "/srv/demo/rover-compose.yaml",
+		},
+		{
+			name: "minimal",
+			state: model.ContainerState{
+				Name:    "db",
+				Project: "demo",
+				Service: "db",
+				Replica: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inspection := &Inspection{}
+			inspection.Config.Labels = Labels(tt.state, tt.configFile)
+
+			// 重建時只知道容器名稱，其餘欄位都來自 label
+			rebuilt := model.ContainerState{Name: tt.state.Name}
+			inspection.ApplyLabels(&rebuilt)
+			if !reflect.DeepEqual(rebuilt, tt.state) {
+				t.Errorf("rebuilt state = %+v, want %+v", rebuilt, tt.state)
+			}
+			if got := inspection.ConfigFile(); got != tt.configFile {
+				t.Errorf("ConfigFile() = %q, want %q", got, tt.configFile)
+			}
+		})
+	}
+}
+
+func TestApplyLabelsReplacesStaleFields(t *testing.T) {
+	inspection := &Inspection{}
+	inspection.Config.Labels = Labels(model.ContainerState{Project: "demo", Service: "web", Revision: 3}, "")
+
+	state := model.ContainerState{Name: "web", ConfigHash: "stale", Revision: 5, Profiles: []string{"old"}}
+	inspection.ApplyLabels(&state)
+	if state.ConfigHash != "" || state.Revision != 3 || state.Profiles != nil {
+		t.Errorf("ApplyLabels() kept stale fields: %+v", state)
+	}
+}
+
+func TestApplyLabelsDefaults(t *testing.T) {
+	inspection := &Inspection{}
+	inspection.Config.Labels = map[string]string{LabelProject: "demo"}
+
+	state := model.ContainerState{Name: "web"}
+	inspection.ApplyLabels(&state)
+	if state.Service != "web" || state.Replica != 1 {
+		t.Errorf("ApplyLabels() without a service label = %+v, want service web and replica 1", state)
+	}
+}
@@ -0,0 +1,52 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Provider 依 key 取得 secret 的內容
+type Provider interface {
+	Lookup(key string) ([]byte, error)
+}
+
+// Environment 從環境變數讀取 secret，變數名稱為 Prefix + key
+type Environment struct {
+	Prefix string
+}
+
+func (e Environment) Lookup(key string) ([]byte, error) {
+	value, ok := os.LookupEnv(e.Prefix + key)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s is not set", e.Prefix+key)
+	}
+	return []byte(value), nil
+}
+
+// Command 執行外部指令取得 secret：key 作為最後一個參數並透過 ROVER_SECRET_KEY 傳入，
+// 指令的標準輸出即為內容（移除結尾的換行）
+type Command struct {
+	Args []string
+	Dir  string
+}
+
+func (c Command) Lookup(key string) ([]byte, error) {
+	if len(c.Args) == 0 {
+		return nil, fmt.Errorf("no command configured")
+	}
+
+	cmd := exec.Command(c.Args[0], append(c.Args[1:], key)...)
+	cmd.Dir = c.Dir
+	cmd.Env = append(os.Environ(), "ROVER_SECRET_KEY="+key)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// 錯誤訊息只包含 stderr，避免內容出現在輸出中
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("command %s failed: %v: %s", c.Args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return bytes.TrimSuffix(bytes.TrimSuffix(stdout.Bytes(), []byte("\n")), []byte("\r")), nil
+}
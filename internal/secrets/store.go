@@ -0,0 +1,256 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// DefaultPassphraseEnv 未設定 key_file 時讀取密語的環境變數
+const DefaultPassphraseEnv = "ROVER_SECRET_PASSPHRASE"
+
+// storeVersion 目前的加密檔格式版本
+const storeVersion = 1
+
+// scrypt 參數
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltSize     = 16
+)
+
+// ErrWrongKey 密語或金鑰檔錯誤，或檔案遭到竄改
+var ErrWrongKey = errors.New("unable to decrypt secret store: wrong passphrase or key file")
+
+// storeFile 加密檔的格式：所有 secret 以 JSON 編碼後，使用 scrypt 衍生的金鑰以 AES-256-GCM 加密
+type storeFile struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// Store 以密語或金鑰檔加密的本機 secret 檔案；明文只存在記憶體中
+type Store struct {
+	path    string
+	salt    []byte
+	key     []byte
+	secrets map[string]string
+}
+
+// DefaultStorePath 回傳專案預設的加密檔路徑，位於使用者設定目錄而非專案目錄
+func DefaultStorePath(project string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rover", "secrets", project+".enc"), nil
+}
+
+// OpenStore 以 material（密語或金鑰檔內容）開啟加密檔；檔案不存在時回傳空的 Store
+func OpenStore(path string, material []byte) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		key, err := deriveKey(material, salt)
+		if err != nil {
+			return nil, err
+		}
+		return &Store{path: path, salt: salt, key: key, secrets: make(map[string]string)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid secret store %s: %v", path, err)
+	}
+	if file.Version != storeVersion || file.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported secret store %s (version %d, kdf %q)", path, file.Version, file.KDF)
+	}
+	if len(file.Salt) != saltSize {
+		return nil, fmt.Errorf("invalid secret store %s: salt is %d bytes, want %d", path, len(file.Salt), saltSize)
+	}
+
+	key, err := deriveKey(material, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	// 損毀或被修改的 nonce 長度會讓 gcm.Open panic，需先檢查
+	if len(file.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid secret store %s: nonce is %d bytes, want %d", path, len(file.Nonce), gcm.NonceSize())
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+
+	store := &Store{path: path, salt: file.Salt, key: key}
+	if err := json.Unmarshal(plaintext, &store.secrets); err != nil {
+		return nil, fmt.Errorf("invalid secret store %s: %v", path, err)
+	}
+	if store.secrets == nil {
+		store.secrets = make(map[string]string)
+	}
+	return store, nil
+}
+
+// Path 回傳加密檔路徑
+func (s *Store) Path() string {
+	return s.path
+}
+
+func (s *Store) Lookup(key string) ([]byte, error) {
+	value, ok := s.secrets[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s not found in %s", key, s.path)
+	}
+	return []byte(value), nil
+}
+
+// Set 設定 secret；需呼叫 Save 才會寫入檔案
+func (s *Store) Set(key string, value []byte) {
+	s.secrets[key] = string(value)
+}
+
+// Delete 刪除 secret；不存在時回傳 false
+func (s *Store) Delete(key string) bool {
+	_, ok := s.secrets[key]
+	delete(s.secrets, key)
+	return ok
+}
+
+// Keys 回傳所有 secret 名稱
+func (s *Store) Keys() []string {
+	keys := make([]string, 0, len(s.secrets))
+	for k := range s.secrets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Save 以新的 nonce 加密並寫入檔案；先寫入暫存檔再改名，避免中斷時留下損毀的檔案
+func (s *Store) Save() error {
+	plaintext, err := json.Marshal(s.secrets)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(s.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(storeFile{
+		Version: storeVersion,
+		KDF:     "scrypt",
+		Salt:    s.salt,
+		Nonce:   nonce,
+		Data:    gcm.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// ReadKeyMaterial 取得解密用的密語：優先讀取 keyFile，其次為 passphraseEnv（預設 ROVER_SECRET_PASSPHRASE），
+// 都沒有時在終端機上提示輸入。confirm 為 true 時（建立新的加密檔）提示輸入兩次，兩次不同時回傳錯誤，
+// 避免打錯的密語讓加密檔無法再開啟
+func ReadKeyMaterial(keyFile, passphraseEnv string, confirm bool) ([]byte, error) {
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		material := bytes.TrimSpace(data)
+		if len(material) == 0 {
+			return nil, fmt.Errorf("key file %s is empty", keyFile)
+		}
+		return material, nil
+	}
+
+	if passphraseEnv == "" {
+		passphraseEnv = DefaultPassphraseEnv
+	}
+	if passphrase, ok := os.LookupEnv(passphraseEnv); ok && passphrase != "" {
+		return []byte(passphrase), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("no passphrase: set %s or configure a key_file", passphraseEnv)
+	}
+	passphrase, err := promptPassphrase(fd, "🔑 Secret store passphrase: ")
+	if err != nil || !confirm {
+		return passphrase, err
+	}
+	again, err := promptPassphrase(fd, "🔑 Repeat the passphrase for the new secret store: ")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(passphrase, again) {
+		return nil, errors.New("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// promptPassphrase 在終端機上不回顯地讀取密語
+func promptPassphrase(fd int, prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(passphrase))) == 0 {
+		return nil, fmt.Errorf("empty passphrase")
+	}
+	return passphrase, nil
+}
+
+func deriveKey(material, salt []byte) ([]byte, error) {
+	return scrypt.Key(material, salt, scryptN, scryptR, scryptP, scryptKeyLen)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
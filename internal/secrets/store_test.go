@@ -0,0 +1,218 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "project.enc")
+
+	store, err := OpenStore(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("OpenStore() on missing file error = %v", err)
+	}
+	if len(store.Keys()) != 0 {
+		t.Fatalf("new store keys = %v, want none", store.Keys())
+	}
+	store.Set("db_password", []byte("s3cr3t-value"))
+	store.Set("api_token", []byte("token-value"))
+	store.Set("removed", []byte("gone"))
+	if !store.Delete("removed") {
+		t.Fatal("Delete(removed) = false, want true")
+	}
+	if store.Delete("missing") {
+		t.Fatal("Delete(missing) = true, want false")
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat store file: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("store file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	reopened, err := OpenStore(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	if got, want := strings.Join(reopened.Keys(), ","), "api_token,db_password"; got != want {
+		t.Errorf("Keys() = %s, want %s", got, want)
+	}
+	value, err := reopened.Lookup("db_password")
+	if err != nil {
+		t.Fatalf("Lookup(db_password) error = %v", err)
+	}
+	if string(value) != "s3cr3t-value" {
+		t.Errorf("Lookup(db_password) = %q, want %q", value, "s3cr3t-value")
+	}
+	if _, err := reopened.Lookup("removed"); err == nil {
+		t.Error("Lookup(removed) succeeded, want error")
+	}
+}
+
+func TestStoreWrongKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "project.enc")
+	saveStore(t, path, "right", map[string]string{"key": "value"})
+
+	if _, err := OpenStore(path, []byte("wrong")); !errors.Is(err, ErrWrongKey) {
+		t.Fatalf("OpenStore() with wrong passphrase error = %v, want ErrWrongKey", err)
+	}
+}
+
+func TestStoreFileHasNoPlaintext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "project.enc")
+	saveStore(t, path, "passphrase", map[string]string{"db_password": "plaintext-secret-value"})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read store file: %v", err)
+	}
+	for _, needle := range []string{"plaintext-secret-value", "db_password", "passphrase"} {
+		if bytes.Contains(data, []byte(needle)) {
+			t.Errorf("store file contains %q in clear text", needle)
+		}
+	}
+}
+
+func TestOpenStoreRejectsCorruptedFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(file *storeFile)
+		want   error
+	}{
+		{
+			name:   "truncated nonce",
+			modify: func(file *storeFile) { file.Nonce = file.Nonce[:4] },
+		},
+		{
+			name:   "empty nonce",
+			modify: func(file *storeFile) { file.Nonce = nil },
+		},
+		{
+			name:   "truncated salt",
+			modify: func(file *storeFile) { file.Salt = file.Salt[:8] },
+		},
+		{
+			name:   "tampered data",
+			modify: func(file *storeFile) { file.Data[0] ^= 0xff },
+			want:   ErrWrongKey,
+		},
+		{
+			name:   "truncated data",
+			modify: func(file *storeFile) { file.Data = file.Data[:2] },
+			want:   ErrWrongKey,
+		},
+		{
+			name:   "unknown version",
+			modify: func(file *storeFile) { file.Version = 99 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "project.enc")
+			saveStore(t, path, "passphrase", map[string]string{"key": "value"})
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read store file: %v", err)
+			}
+			var file storeFile
+			if err := json.Unmarshal(data, &file); err != nil {
+				t.Fatalf("decode store file: %v", err)
+			}
+			tt.modify(&file)
+			data, err = json.Marshal(file)
+			if err != nil {
+				t.Fatalf("encode store file: %v", err)
+			}
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				t.Fatalf("write store file: %v", err)
+			}
+
+			_, err = OpenStore(path, []byte("passphrase"))
+			if err == nil {
+				t.Fatal("OpenStore() succeeded on a corrupted file")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("OpenStore() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid json", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "project.enc")
+		if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+			t.Fatalf("write store file: %v", err)
+		}
+		if _, err := OpenStore(path, []byte("passphrase")); err == nil || !strings.Contains(err.Error(), "invalid secret store") {
+			t.Errorf("OpenStore() error = %v, want invalid secret store", err)
+		}
+	})
+}
+
+func TestEnvironmentProvider(t *testing.T) {
+	t.Setenv("ROVER_TEST_DB_PASSWORD", "from-env")
+
+	provider := Environment{Prefix: "ROVER_TEST_"}
+	value, err := provider.Lookup("DB_PASSWORD")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if string(value) != "from-env" {
+		t.Errorf("Lookup() = %q, want %q", value, "from-env")
+	}
+	if _, err := provider.Lookup("MISSING_ROVER_SECRET"); err == nil {
+		t.Error("Lookup() of an unset variable succeeded, want error")
+	}
+}
+
+func TestCommandProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("command provider test uses sh")
+	}
+
+	provider := Command{Args: []string{"sh", "-c", `printf '%s:%s\n' "$1" "$ROVER_SECRET_KEY"`, "sh"}}
+	value, err := provider.Lookup("api_token")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if string(value) != "api_token:api_token" {
+		t.Errorf("Lookup() = %q, want %q", value, "api_token:api_token")
+	}
+
+	failing := Command{Args: []string{"sh", "-c", "echo boom >&2; exit 3"}}
+	_, err = failing.Lookup("api_token")
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Lookup() error = %v, want stderr in the error", err)
+	}
+
+	if _, err := (Command{}).Lookup("api_token"); err == nil {
+		t.Error("Lookup() without a command succeeded, want error")
+	}
+}
+
+func saveStore(t *testing.T, path, passphrase string, values map[string]string) {
+	t.Helper()
+	store, err := OpenStore(path, []byte(passphrase))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	for key, value := range values {
+		store.Set(key, []byte(value))
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+}
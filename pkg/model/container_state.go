@@ -4,10 +4,43 @@ import (
 	"time"
 )
 
-// ContainerState 定義容器狀態存儲
+// ContainerState 定義容器狀態存儲；執行環境相關的欄位於每次操作後由 podman inspect 更新
 type ContainerState struct {
-	Name      string    `json:"name"`
-	ID        string    `json:"id"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	Name         string          `json:"name"`
+	ID           string          `json:"id"`
+	Status       ContainerStatus `json:"status"`
+	Project      string          `json:"project,omitempty"`
+	Service      string          `json:"service,omitempty"`
+	Replica      int             `json:"replica,omitempty"` // 同一服務的第幾個容器，從 1 開始
+	Image        string          `json:"image,omitempty"`
+	ImageDigest  string          `json:"image_digest,omitempty"`
+	ConfigHash   string          `json:"config_hash,omitempty"`
+	Revision     int             `json:"revision,omitempty"` // 建立容器的部署版本
+	Profiles     []string        `json:"profiles,omitempty"`
+	Ports        []PortBinding   `json:"ports,omitempty"`
+	Mounts       []Mount         `json:"mounts,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	StartedAt    time.Time       `json:"started_at"`
+	FinishedAt   time.Time       `json:"finished_at"`
+	ExitCode     int             `json:"exit_code"`
+	RestartCount int             `json:"restart_count"`
+
+	StatusChangedAt time.Time      `json:"status_changed_at"`
+	History         []StatusChange `json:"history,omitempty"` // 最近的狀態變更
+}
+
+// PortBinding 容器實際發佈的連接埠
+type PortBinding struct {
+	HostIP        string `json:"host_ip,omitempty"`
+	HostPort      int    `json:"host_port"`
+	ContainerPort int    `json:"container_port"`
+	Protocol      string `json:"protocol"`
+}
+
+// Mount 容器實際的掛載
+type Mount struct {
+	Type        string `json:"type"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination"`
+	ReadOnly    bool   `json:"read_only,omitempty"`
 }
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+// Event 記錄容器或專案發生的事件
+type Event struct {
+	ID        uint64    `json:"id"`
+	Project   string    `json:"project,omitempty"`
+	Container string    `json:"container,omitempty"`
+	Type      string    `json:"type"`
+	Message   string    `json:"message,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// 事件類型
+const (
+	EventDeployed     = "deployed"      // 部署版本建立並啟動容器
+	EventDeployFailed = "deploy_failed" // 容器無法啟動、未就緒或因依賴失敗而略過
+	EventSetAside     = "set_aside"     // atomic 部署停止並保留被取代的容器；Message 為保留前的執行狀態
+	EventRestored     = "restored"      // atomic 部署失敗後還原先前的容器
+	EventRollback     = "rollback"      // rover rollback 重新部署先前的版本
+	EventStopped      = "stopped"       // rover down 停止並移除容器
+)
@@ -0,0 +1,161 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// ContainerStatus 容器生命週期的狀態
+type ContainerStatus string
+
+const (
+	StatusCreated   ContainerStatus = "created"
+	StatusStarting  ContainerStatus = "starting"
+	StatusRunning   ContainerStatus = "running"
+	StatusHealthy   ContainerStatus = "healthy"
+	StatusUnhealthy ContainerStatus = "unhealthy"
+	StatusExited    ContainerStatus = "exited"
+	StatusFailed    ContainerStatus = "failed"
+	StatusRemoving  ContainerStatus = "removing"
+	StatusRemoved   ContainerStatus = "removed"
+)
+
+// maxStatusHistory 每個容器保留的狀態變更筆數
+const maxStatusHistory = 20
+
+// transitions 每個狀態可轉換到的下一個狀態；空字串為尚未記錄的新容器
+var transitions = map[ContainerStatus][]ContainerStatus{
+	"":              {StatusCreated, StatusStarting},
+	StatusCreated:   {StatusStarting, StatusRunning, StatusExited, StatusFailed, StatusRemoving},
+	StatusStarting:  {StatusRunning, StatusHealthy, StatusUnhealthy, StatusExited, StatusFailed, StatusRemoving},
+	StatusRunning:   {StatusStarting, StatusHealthy, StatusUnhealthy, StatusExited, StatusFailed, StatusRemoving},
+	StatusHealthy:   {StatusStarting, StatusRunning, StatusUnhealthy, StatusExited, StatusFailed, StatusRemoving},
+	StatusUnhealthy: {StatusStarting, StatusRunning, StatusHealthy, StatusExited, StatusFailed, StatusRemoving},
+	StatusExited:    {StatusStarting, StatusRunning, StatusHealthy, StatusUnhealthy, StatusFailed, StatusRemoving},
+	StatusFailed:    {StatusStarting, StatusRunning, StatusExited, StatusRemoving},
+	StatusRemoving:  {StatusRemoved, StatusFailed},
+	StatusRemoved:   {StatusCreated, StatusStarting},
+}
+
+// externalTransitions 執行環境在 Rover 操作之外造成、transitions 不允許的狀態變更：
+// 兩次觀察之間跳過了中間狀態，或 podman 已停止但尚未刪除移除中的容器
+var externalTransitions = map[ContainerStatus][]ContainerStatus{
+	StatusCreated:  {StatusHealthy, StatusUnhealthy},
+	StatusFailed:   {StatusHealthy, StatusUnhealthy},
+	StatusRemoving: {StatusExited},
+}
+
+// StatusChange 一次狀態變更
+type StatusChange struct {
+	From       ContainerStatus `json:"from,omitempty"`
+	To         ContainerStatus `json:"to"`
+	At         time.Time       `json:"at"`
+	Unexpected bool            `json:"unexpected,omitempty"` // 觀察到 CanObserve 不允許的變更
+}
+
+// Valid 是否為已定義的狀態
+func (s ContainerStatus) Valid() bool {
+	_, ok := transitions[s]
+	return ok && s != ""
+}
+
+// CanTransition 是否可從 s 轉換到 to；維持相同狀態一律允許
+func (s ContainerStatus) CanTransition(to ContainerStatus) bool {
+	if s == to {
+		return true
+	}
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanObserve 執行環境是否可能從 s 變為 to：transitions 允許的轉換，或 externalTransitions 列出的變更；
+// 尚未記錄狀態的容器可觀察到任何狀態
+func (s ContainerStatus) CanObserve(to ContainerStatus) bool {
+	if s == "" || s.CanTransition(to) {
+		return true
+	}
+	for _, next := range externalTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Active 容器是否仍在執行
+func (s ContainerStatus) Active() bool {
+	switch s {
+	case StatusStarting, StatusRunning, StatusHealthy, StatusUnhealthy:
+		return true
+	}
+	return false
+}
+
+// Transition 由 Rover 的操作變更狀態，不合法的轉換回傳錯誤
+func (c *ContainerState) Transition(to ContainerStatus, at time.Time) error {
+	if !to.Valid() {
+		return fmt.Errorf("container %s: unknown status %q", c.Name, to)
+	}
+	if !c.Status.CanTransition(to) {
+		return fmt.Errorf("container %s: invalid status transition %s -> %s", c.Name, c.Status, to)
+	}
+	c.setStatus(to, at, false)
+	return nil
+}
+
+// Observe 記錄從執行環境觀察到的狀態。執行環境的狀態以實際為準，CanObserve 不允許的變更
+// 仍會記錄，但在歷程中標記為 unexpected 並回傳錯誤，由呼叫端警告
+func (c *ContainerState) Observe(status ContainerStatus, at time.Time) error {
+	if !status.Valid() {
+		return fmt.Errorf("container %s: unknown status %q", c.Name, status)
+	}
+	if c.Status.CanObserve(status) {
+		c.setStatus(status, at, false)
+		return nil
+	}
+	from := c.Status
+	c.setStatus(status, at, true)
+	return fmt.Errorf("container %s: unexpected status change %s -> %s outside rover", c.Name, from, status)
+}
+
+func (c *ContainerState) setStatus(to ContainerStatus, at time.Time, unexpected bool) {
+	if c.Status == to {
+		return
+	}
+	c.History = append(c.History, StatusChange{From: c.Status, To: to, At: at, Unexpected: unexpected})
+	if len(c.History) > maxStatusHistory {
+		c.History = c.History[len(c.History)-maxStatusHistory:]
+	}
+	c.Status = to
+	c.StatusChangedAt = at
+}
+
+// StatusFromRuntime 將 podman 的狀態、健康檢查結果與結束代碼轉換為 ContainerStatus
+func StatusFromRuntime(status, health string, exitCode int) ContainerStatus {
+	switch status {
+	case "created", "configured", "initialized":
+		return StatusCreated
+	case "running", "paused":
+		switch health {
+		case "healthy":
+			return StatusHealthy
+		case "unhealthy":
+			return StatusUnhealthy
+		case "starting":
+			return StatusStarting
+		}
+		return StatusRunning
+	case "exited", "stopped":
+		if exitCode != 0 {
+			return StatusFailed
+		}
+		return StatusExited
+	case "stopping", "removing":
+		return StatusRemoving
+	}
+	return StatusFailed
+}
@@ -0,0 +1,131 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		from  ContainerStatus
+		to    ContainerStatus
+		valid bool
+	}{
+		{"", StatusStarting, true},
+		{"", StatusRunning, false},
+		{StatusStarting, StatusRunning, true},
+		{StatusRunning, StatusRemoving, true},
+		{StatusRemoving, StatusRemoved, true},
+		{StatusRemoved, StatusStarting, true},
+		{StatusRemoved, StatusRunning, false},
+		{StatusRemoving, StatusRunning, false},
+		{StatusExited, StatusCreated, false},
+		{StatusRunning, StatusRunning, true},
+		{StatusRunning, "paused", false},
+	}
+
+	for _, tt := range tests {
+		state := ContainerState{Name: "web", Status: tt.from}
+		err := state.Transition(tt.to, time.Now())
+		if (err == nil) != tt.valid {
+			t.Errorf("%q -> %q: got error %v, want valid %v", tt.from, tt.to, err, tt.valid)
+			continue
+		}
+		want := tt.to
+		if !tt.valid {
+			want = tt.from
+		}
+		if state.Status != want {
+			t.Errorf("%q -> %q: status is %q, want %q", tt.from, tt.to, state.Status, want)
+		}
+	}
+}
+
+func TestObserve(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     ContainerStatus
+		to       ContainerStatus
+		expected bool
+	}{
+		{"first observation", "", StatusRunning, true},
+		{"process exited", StatusRunning, StatusExited, true},
+		{"process crashed", StatusHealthy, StatusFailed, true},
+		{"restart policy", StatusFailed, StatusRunning, true},
+		{"health check passed", StatusStarting, StatusHealthy, true},
+		{"skipped starting", StatusCreated, StatusHealthy, true},
+		{"stopped during removal", StatusRemoving, StatusExited, true},
+		{"removed container running again", StatusRemoved, StatusRunning, false},
+		{"restarted during removal", StatusRemoving, StatusRunning, false},
+		{"recreated outside rover", StatusRunning, StatusCreated, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := ContainerState{Name: "web", Status: tt.from}
+			err := state.Observe(tt.to, time.Now())
+			if (err == nil) != tt.expected {
+				t.Fatalf("got error %v, want expected %v", err, tt.expected)
+			}
+			// 執行環境的狀態一律記錄，非預期的變更在歷程中標記
+			if state.Status != tt.to {
+				t.Errorf("status is %q, want %q", state.Status, tt.to)
+			}
+			last := state.History[len(state.History)-1]
+			if last.From != tt.from || last.To != tt.to || last.Unexpected == tt.expected {
+				t.Errorf("history entry %+v, want %q -> %q unexpected=%v", last, tt.from, tt.to, !tt.expected)
+			}
+		})
+	}
+}
+
+func TestObserveUnchanged(t *testing.T) {
+	state := ContainerState{Name: "web", Status: StatusRunning}
+	if err := state.Observe(StatusRunning, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.History) != 0 {
+		t.Errorf("observing the same status added history %+v", state.History)
+	}
+}
+
+func TestStatusHistoryLimit(t *testing.T) {
+	state := ContainerState{Name: "web"}
+	now := time.Now()
+	for i := 0; i < maxStatusHistory+5; i++ {
+		next := StatusStarting
+		if state.Status == StatusStarting {
+			next = StatusRunning
+		}
+		if err := state.Transition(next, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(state.History) != maxStatusHistory {
+		t.Errorf("kept %d history entries, want %d", len(state.History), maxStatusHistory)
+	}
+}
+
+func TestStatusFromRuntime(t *testing.T) {
+	tests := []struct {
+		status   string
+		health   string
+		exitCode int
+		want     ContainerStatus
+	}{
+		{"created", "", 0, StatusCreated},
+		{"running", "", 0, StatusRunning},
+		{"running", "healthy", 0, StatusHealthy},
+		{"running", "unhealthy", 0, StatusUnhealthy},
+		{"running", "starting", 0, StatusStarting},
+		{"exited", "", 0, StatusExited},
+		{"exited", "", 137, StatusFailed},
+		{"stopping", "", 0, StatusRemoving},
+		{"unknown", "", 0, StatusFailed},
+	}
+	for _, tt := range tests {
+		if got := StatusFromRuntime(tt.status, tt.health, tt.exitCode); got != tt.want {
+			t.Errorf("StatusFromRuntime(%q, %q, %d) = %q, want %q", tt.status, tt.health, tt.exitCode, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+// ProjectState 記錄專案最近一次 apply 時使用的設定
+type ProjectState struct {
+	Name       string    `json:"name"`
+	ConfigFile string    `json:"config_file"`
+	Profiles   []string  `json:"profiles"`
+	Services   []string  `json:"services"`
+	Secrets    []string  `json:"secrets,omitempty"` // Podman secret 名稱，不含內容
+	UpdatedAt  time.Time `json:"updated_at"`
+}
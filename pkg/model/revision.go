@@ -0,0 +1,37 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 部署的結果
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+	// OutcomeRolledBack atomic 部署失敗，已還原部署前的容器
+	OutcomeRolledBack = "rolled_back"
+)
+
+// Revision 記錄專案的一次部署；存儲後不再修改
+type Revision struct {
+	Project    string            `json:"project"`
+	Number     int               `json:"number"`
+	User       string            `json:"user,omitempty"`
+	Outcome    string            `json:"outcome,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	ConfigFile string            `json:"config_file,omitempty"`
+	Profiles   []string          `json:"profiles,omitempty"`
+	Services   []ServiceRevision `json:"services,omitempty"`    // 本次部署的服務，依啟動順序
+	Compose    json.RawMessage   `json:"compose,omitempty"`     // 解析並套用 profiles 後的完整專案
+	RollbackOf int               `json:"rollback_of,omitempty"` // 回復到的版本，一般部署為 0
+}
+
+// ServiceRevision 服務在部署版本中的設定與結果
+type ServiceRevision struct {
+	Name        string          `json:"name"`
+	ConfigHash  string          `json:"config_hash"`
+	Image       string          `json:"image,omitempty"`
+	ImageDigest string          `json:"image_digest,omitempty"`
+	Status      ContainerStatus `json:"status,omitempty"` // 部署結束時的容器狀態，未啟動時為空
+}
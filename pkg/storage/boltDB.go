@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,15 +15,20 @@ import (
 var (
 	// 定義 BoltDB 存儲的 Bucket 名稱
 	containerBucket = []byte("containers")
+	projectBucket   = []byte("projects")
+	revisionBucket  = []byte("revisions") // 每個專案一個子 bucket，key 為版本號
+	eventBucket     = []byte("events")    // key 為遞增序號
 
-	// 常見錯誤
-	ErrBucketNotFound    = errors.New("storage bucket not found")
-	ErrContainerNotFound = errors.New("container not found")
+	// ErrBucketNotFound BoltDB 缺少 bucket
+	ErrBucketNotFound = errors.New("storage bucket not found")
 )
 
 // BoltDB 存儲管理
 type BoltDB struct {
 	db *bbolt.DB
+
+	migratedFrom int    // 開啟時執行 migration 前的 schema 版本，沒有執行時為 0
+	backupPath   string // migration 前的備份路徑
 }
 
 // NewBoltDB 初始化 BoltDB
@@ -37,16 +43,23 @@ func NewBoltDB(dbPath string) (*BoltDB, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// 確保 "containers" bucket 存在
-	if err := db.Update(func(tx *bbolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(containerBucket)
-		return err
-	}); err != nil {
+	// 建立或升級 schema
+	from, backup, err := migrate(db, dbPath)
+	if err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to create bucket: %w", err)
+		return nil, err
+	}
+
+	b := &BoltDB{db: db, backupPath: backup}
+	if backup != "" {
+		b.migratedFrom = from
 	}
+	return b, nil
+}
 
-	return &BoltDB{db: db}, nil
+// Migrated 回傳開啟時執行 migration 前的 schema 版本與備份路徑；沒有執行 migration 時版本為 0
+func (b *BoltDB) Migrated() (int, string) {
+	return b.migratedFrom, b.backupPath
 }
 
 // SaveContainer 存儲容器狀態
@@ -56,18 +69,22 @@ func (b *BoltDB) SaveContainer(container model.ContainerState) error {
 	}
 
 	return b.db.Update(func(tx *bbolt.Tx) error {
-		bucket := tx.Bucket(containerBucket)
-		if bucket == nil {
-			return ErrBucketNotFound
-		}
+		return putContainer(tx, container)
+	})
+}
 
-		data, err := json.Marshal(container)
-		if err != nil {
-			return fmt.Errorf("failed to marshal container: %w", err)
-		}
+func putContainer(tx *bbolt.Tx, container model.ContainerState) error {
+	bucket := tx.Bucket(containerBucket)
+	if bucket == nil {
+		return ErrBucketNotFound
+	}
 
-		return bucket.Put([]byte(container.Name), data)
-	})
+	data, err := json.Marshal(container)
+	if err != nil {
+		return fmt.Errorf("failed to marshal container: %w", err)
+	}
+
+	return bucket.Put([]byte(container.Name), data)
 }
 
 // GetContainer 取得單個容器
@@ -132,6 +149,291 @@ func (b *BoltDB) DeleteContainer(name string) error {
 	})
 }
 
+// SaveProject 存儲專案狀態
+func (b *BoltDB) SaveProject(project model.ProjectState) error {
+	if project.Name == "" {
+		return errors.New("project name cannot be empty")
+	}
+
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		return putProject(tx, project)
+	})
+}
+
+func putProject(tx *bbolt.Tx, project model.ProjectState) error {
+	bucket := tx.Bucket(projectBucket)
+	if bucket == nil {
+		return ErrBucketNotFound
+	}
+
+	data, err := json.Marshal(project)
+	if err != nil {
+		return fmt.Errorf("failed to marshal project: %w", err)
+	}
+
+	return bucket.Put([]byte(project.Name), data)
+}
+
+// GetProject 取得單個專案
+func (b *BoltDB) GetProject(name string) (*model.ProjectState, error) {
+	var project model.ProjectState
+
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(projectBucket)
+		if bucket == nil {
+			return ErrBucketNotFound
+		}
+
+		data := bucket.Get([]byte(name))
+		if data == nil {
+			return ErrProjectNotFound
+		}
+
+		return json.Unmarshal(data, &project)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// GetProjects 取得所有專案
+func (b *BoltDB) GetProjects() ([]model.ProjectState, error) {
+	var projects []model.ProjectState
+
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(projectBucket)
+		if bucket == nil {
+			return ErrBucketNotFound
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var project model.ProjectState
+			if err := json.Unmarshal(v, &project); err != nil {
+				return fmt.Errorf("failed to unmarshal project %s: %w", k, err)
+			}
+			projects = append(projects, project)
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// DeleteProject 刪除專案
+func (b *BoltDB) DeleteProject(name string) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(projectBucket)
+		if bucket == nil {
+			return ErrBucketNotFound
+		}
+
+		return bucket.Delete([]byte(name))
+	})
+}
+
+// SaveRevision 存儲部署版本；Number 為 0 時指派下一個版本號，既有的版本不可覆寫
+func (b *BoltDB) SaveRevision(revision *model.Revision) error {
+	if revision.Project == "" {
+		return errors.New("revision project cannot be empty")
+	}
+
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		return putRevision(tx, revision)
+	})
+}
+
+func putRevision(tx *bbolt.Tx, revision *model.Revision) error {
+	root := tx.Bucket(revisionBucket)
+	if root == nil {
+		return ErrBucketNotFound
+	}
+	bucket, err := root.CreateBucketIfNotExists([]byte(revision.Project))
+	if err != nil {
+		return err
+	}
+
+	if revision.Number == 0 {
+		revision.Number = 1
+		if last, _ := bucket.Cursor().Last(); last != nil {
+			revision.Number = int(binary.BigEndian.Uint64(last)) + 1
+		}
+	}
+
+	key := sequenceKey(uint64(revision.Number))
+	if bucket.Get(key) != nil {
+		return fmt.Errorf("revision %d of project %s: %w", revision.Number, revision.Project, ErrRevisionExists)
+	}
+
+	data, err := json.Marshal(revision)
+	if err != nil {
+		return fmt.Errorf("failed to marshal revision: %w", err)
+	}
+	return bucket.Put(key, data)
+}
+
+// GetRevision 取得專案的指定版本
+func (b *BoltDB) GetRevision(project string, number int) (*model.Revision, error) {
+	var revision model.Revision
+
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		root := tx.Bucket(revisionBucket)
+		if root == nil {
+			return ErrBucketNotFound
+		}
+		bucket := root.Bucket([]byte(project))
+		if bucket == nil || number <= 0 {
+			return ErrRevisionNotFound
+		}
+
+		data := bucket.Get(sequenceKey(uint64(number)))
+		if data == nil {
+			return ErrRevisionNotFound
+		}
+		return json.Unmarshal(data, &revision)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// GetRevisions 依版本號排序取得專案的所有版本；project 為空字串時回傳所有專案的版本
+func (b *BoltDB) GetRevisions(project string) ([]model.Revision, error) {
+	var revisions []model.Revision
+
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		root := tx.Bucket(revisionBucket)
+		if root == nil {
+			return ErrBucketNotFound
+		}
+
+		read := func(bucket *bbolt.Bucket) error {
+			return bucket.ForEach(func(k, v []byte) error {
+				var revision model.Revision
+				if err := json.Unmarshal(v, &revision); err != nil {
+					return fmt.Errorf("failed to unmarshal revision %d: %w", binary.BigEndian.Uint64(k), err)
+				}
+				revisions = append(revisions, revision)
+				return nil
+			})
+		}
+
+		if project == "" {
+			return root.ForEachBucket(func(name []byte) error {
+				return read(root.Bucket(name))
+			})
+		}
+		bucket := root.Bucket([]byte(project))
+		if bucket == nil {
+			return nil
+		}
+		return read(bucket)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// AddEvent 依序存儲事件
+func (b *BoltDB) AddEvent(event model.Event) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		return putEvent(tx, event)
+	})
+}
+
+func putEvent(tx *bbolt.Tx, event model.Event) error {
+	bucket := tx.Bucket(eventBucket)
+	if bucket == nil {
+		return ErrBucketNotFound
+	}
+
+	id, err := bucket.NextSequence()
+	if err != nil {
+		return err
+	}
+	event.ID = id
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return bucket.Put(sequenceKey(id), data)
+}
+
+// GetEvents 依發生順序取得事件；project 為空字串時回傳所有事件
+func (b *BoltDB) GetEvents(project string) ([]model.Event, error) {
+	var events []model.Event
+
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(eventBucket)
+		if bucket == nil {
+			return ErrBucketNotFound
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var event model.Event
+			if err := json.Unmarshal(v, &event); err != nil {
+				return fmt.Errorf("failed to unmarshal event %d: %w", binary.BigEndian.Uint64(k), err)
+			}
+			if project == "" || event.Project == project {
+				events = append(events, event)
+			}
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// SaveBatch 在單一交易中寫入所有記錄；任何一筆失敗時整個交易回復，不會留下部分寫入的記錄
+func (b *BoltDB) SaveBatch(batch Batch) error {
+	if err := batch.validate(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		for _, project := range batch.Projects {
+			if err := putProject(tx, project); err != nil {
+				return err
+			}
+		}
+		for _, container := range batch.Containers {
+			if err := putContainer(tx, container); err != nil {
+				return err
+			}
+		}
+		for i := range batch.Revisions {
+			if err := putRevision(tx, &batch.Revisions[i]); err != nil {
+				return err
+			}
+		}
+		for _, event := range batch.Events {
+			if err := putEvent(tx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// sequenceKey 以 big-endian 編碼序號，讓 bucket 依數值順序排列
+func sequenceKey(n uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, n)
+	return key
+}
+
 // Close 關閉 BoltDB
 func (b *BoltDB) Close() error {
 	if b.db == nil {
@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/vvvdwbvvv/rover/pkg/model"
+)
+
+// SnapshotVersion 目前的匯出格式版本；格式不相容地變更時遞增
+const SnapshotVersion = 1
+
+// Snapshot 匯出的完整狀態，與儲存後端無關
+type Snapshot struct {
+	Version    int                    `json:"version"`
+	ExportedAt time.Time              `json:"exported_at"`
+	Projects   []model.ProjectState   `json:"projects"`
+	Containers []model.ContainerState `json:"containers"`
+	Revisions  []model.Revision       `json:"revisions"`
+	Events     []model.Event          `json:"events"`
+}
+
+// Export 讀取 store 的所有狀態
+func Export(store Store) (*Snapshot, error) {
+	snapshot := &Snapshot{Version: SnapshotVersion, ExportedAt: time.Now()}
+	var err error
+	if snapshot.Projects, err = store.GetProjects(); err != nil {
+		return nil, fmt.Errorf("failed to export projects: %w", err)
+	}
+	if snapshot.Containers, err = store.GetContainers(); err != nil {
+		return nil, fmt.Errorf("failed to export containers: %w", err)
+	}
+	if snapshot.Revisions, err = store.GetRevisions(""); err != nil {
+		return nil, fmt.Errorf("failed to export revisions: %w", err)
+	}
+	if snapshot.Events, err = store.GetEvents(""); err != nil {
+		return nil, fmt.Errorf("failed to export events: %w", err)
+	}
+	return snapshot, nil
+}
+
+// Write 以縮排的 JSON 輸出
+func (s *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// ReadSnapshot 讀取並驗證匯出的狀態
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	var snapshot Snapshot
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	if problems := snapshot.Validate(); len(problems) > 0 {
+		return nil, fmt.Errorf("invalid snapshot: %w", errors.Join(problems...))
+	}
+	return &snapshot, nil
+}
+
+// Validate 檢查格式版本、必要欄位與重複的記錄
+func (s *Snapshot) Validate() []error {
+	var problems []error
+	switch {
+	case s.Version == 0:
+		return []error{errors.New("missing version")}
+	case s.Version > SnapshotVersion:
+		return []error{fmt.Errorf("version %d is newer than the supported version %d; upgrade rover", s.Version, SnapshotVersion)}
+	}
+
+	projects := make(map[string]bool)
+	for i, project := range s.Projects {
+		switch {
+		case project.Name == "":
+			problems = append(problems, fmt.Errorf("project %d has no name", i))
+		case projects[project.Name]:
+			problems = append(problems, fmt.Errorf("duplicate project %s", project.Name))
+		}
+		projects[project.Name] = true
+	}
+
+	containers := make(map[string]bool)
+	for i, container := range s.Containers {
+		switch {
+		case container.Name == "":
+			problems = append(problems, fmt.Errorf("container %d has no name", i))
+		case containers[container.Name]:
+			problems = append(problems, fmt.Errorf("duplicate container %s", container.Name))
+		case container.Status != "" && !container.Status.Valid():
+			problems = append(problems, fmt.Errorf("container %s has unknown status %q", container.Name, container.Status))
+		}
+		containers[container.Name] = true
+	}
+
+	revisions := make(map[string]bool)
+	for _, revision := range s.Revisions {
+		key := fmt.Sprintf("%s#%d", revision.Project, revision.Number)
+		switch {
+		case revision.Project == "":
+			problems = append(problems, fmt.Errorf("revision %d has no project", revision.Number))
+		case revision.Number <= 0:
+			problems = append(problems, fmt.Errorf("revision of project %s has invalid number %d", revision.Project, revision.Number))
+		case revisions[key]:
+			problems = append(problems, fmt.Errorf("duplicate revision %d of project %s", revision.Number, revision.Project))
+		}
+		revisions[key] = true
+	}
+
+	for i, event := range s.Events {
+		if event.Type == "" {
+			problems = append(problems, fmt.Errorf("event %d has no type", i))
+		}
+	}
+	return problems
+}
+
+// 匯入時遇到既有記錄的處理方式
+const (
+	ConflictFail      = "fail"      // 有任何衝突時不匯入
+	ConflictSkip      = "skip"      // 保留既有的記錄
+	ConflictOverwrite = "overwrite" // 以匯入的專案與容器取代既有的記錄；部署版本不可修改，衝突時仍不匯入
+)
+
+// ConflictPolicies 可用的衝突處理方式
+var ConflictPolicies = []string{ConflictFail, ConflictSkip, ConflictOverwrite}
+
+// ImportResult 匯入的結果
+type ImportResult struct {
+	Imported  int      // 寫入的記錄數
+	Unchanged int      // 與既有記錄相同而略過的記錄數
+	Conflicts []string // 與既有記錄不同的記錄
+}
+
+// Import 將 snapshot 寫入 store；與既有記錄相同的記錄直接略過，不同時依 policy 處理。
+// 事件沒有唯一的鍵，內容相同視為已匯入，其 ID 由 store 重新指派。dryRun 時只檢查不寫入。
+// 所有記錄以 SaveBatch 一次寫入：bolt 後端在單一交易中寫入，memory 與 json 後端先寫入狀態的副本，
+// json 後端再以暫存檔改名重寫檔案；寫入失敗時不會留下部分匯入的狀態
+func Import(store Store, snapshot *Snapshot, policy string, dryRun bool) (ImportResult, error) {
+	var result ImportResult
+	if problems := snapshot.Validate(); len(problems) > 0 {
+		return result, fmt.Errorf("invalid snapshot: %w", errors.Join(problems...))
+	}
+	switch policy {
+	case ConflictFail, ConflictSkip, ConflictOverwrite:
+	default:
+		return result, fmt.Errorf("unknown conflict policy %q", policy)
+	}
+
+	// 先找出所有衝突，有無法處理的衝突時不寫入任何記錄
+	var batch Batch
+	var blocked []error
+	conflict := func(what string, overwritable bool, write func()) {
+		result.Conflicts = append(result.Conflicts, what)
+		switch {
+		case policy == ConflictSkip:
+		case policy == ConflictOverwrite && overwritable:
+			write()
+		default:
+			blocked = append(blocked, fmt.Errorf("%s already exists with different content", what))
+		}
+	}
+
+	for _, project := range snapshot.Projects {
+		project := project
+		write := func() { batch.Projects = append(batch.Projects, project) }
+		existing, err := store.GetProject(project.Name)
+		switch {
+		case errors.Is(err, ErrProjectNotFound):
+			write()
+		case err != nil:
+			return result, fmt.Errorf("failed to read project %s: %w", project.Name, err)
+		case reflect.DeepEqual(normalize(*existing), normalize(project)):
+			result.Unchanged++
+		default:
+			conflict("project "+project.Name, true, write)
+		}
+	}
+
+	for _, container := range snapshot.Containers {
+		container := container
+		write := func() { batch.Containers = append(batch.Containers, container) }
+		existing, err := store.GetContainer(container.Name)
+		switch {
+		case errors.Is(err, ErrContainerNotFound):
+			write()
+		case err != nil:
+			return result, fmt.Errorf("failed to read container %s: %w", container.Name, err)
+		case reflect.DeepEqual(normalize(*existing), normalize(container)):
+			result.Unchanged++
+		default:
+			conflict("container "+container.Name, true, write)
+		}
+	}
+
+	for _, revision := range snapshot.Revisions {
+		revision := revision
+		write := func() { batch.Revisions = append(batch.Revisions, revision) }
+		existing, err := store.GetRevision(revision.Project, revision.Number)
+		switch {
+		case errors.Is(err, ErrRevisionNotFound):
+			write()
+		case err != nil:
+			return result, fmt.Errorf("failed to read revision %d of project %s: %w", revision.Number, revision.Project, err)
+		case reflect.DeepEqual(normalize(*existing), normalize(revision)):
+			result.Unchanged++
+		default:
+			conflict(fmt.Sprintf("revision %d of project %s", revision.Number, revision.Project), false, write)
+		}
+	}
+
+	existingEvents, err := store.GetEvents("")
+	if err != nil {
+		return result, fmt.Errorf("failed to read events: %w", err)
+	}
+	seen := make(map[string]bool)
+	for _, event := range existingEvents {
+		seen[eventKey(event)] = true
+	}
+	for _, event := range snapshot.Events {
+		if seen[eventKey(event)] {
+			result.Unchanged++
+			continue
+		}
+		batch.Events = append(batch.Events, event)
+	}
+
+	if len(blocked) > 0 {
+		return result, errors.Join(blocked...)
+	}
+	result.Imported = len(batch.Projects) + len(batch.Containers) + len(batch.Revisions) + len(batch.Events)
+	if dryRun {
+		return result, nil
+	}
+	if err := store.SaveBatch(batch); err != nil {
+		return result, fmt.Errorf("import failed, nothing was imported: %w", err)
+	}
+	return result, nil
+}
+
+// normalize 以 JSON 往返消除 nil 與空 slice、時區等不影響內容的差異，供比較記錄是否相同
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return v
+	}
+	return normalized
+}
+
+// eventKey 不含 ID 的事件內容
+func eventKey(event model.Event) string {
+	event.ID = 0
+	data, _ := json.Marshal(event)
+	return string(data)
+}
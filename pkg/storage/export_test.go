@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vvvdwbvvv/rover/pkg/model"
+)
+
+// sampleStore 建立含專案、容器、部署版本與事件的 store
+func sampleStore(t *testing.T) *Memory {
+	t.Helper()
+	store := NewMemory()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	must(t, store.SaveProject(model.ProjectState{Name: "demo", Services: []string{"web"}, UpdatedAt: now}))
+	must(t, store.SaveContainer(model.ContainerState{Name: "web", Project: "demo", Service: "web", Status: model.StatusRunning}))
+	must(t, store.SaveRevision(&model.Revision{Project: "demo", Outcome: model.OutcomeSucceeded, CreatedAt: now}))
+	must(t, store.AddEvent(model.Event{Project: "demo", Container: "web", Type: model.EventDeployed, CreatedAt: now}))
+	return store
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// roundTrip 匯出 store 並以 JSON 讀回
+func roundTrip(t *testing.T, store Store) *Snapshot {
+	t.Helper()
+	snapshot, err := Export(store)
+	must(t, err)
+	var buf bytes.Buffer
+	must(t, snapshot.Write(&buf))
+	read, err := ReadSnapshot(&buf)
+	must(t, err)
+	return read
+}
+
+func TestExportImport(t *testing.T) {
+	snapshot := roundTrip(t, sampleStore(t))
+	if len(snapshot.Projects) != 1 || len(snapshot.Containers) != 1 || len(snapshot.Revisions) != 1 || len(snapshot.Events) != 1 {
+		t.Fatalf("incomplete snapshot: %+v", snapshot)
+	}
+
+	target := NewMemory()
+	result, err := Import(target, snapshot, ConflictFail, false)
+	must(t, err)
+	if result.Imported != 4 || result.Unchanged != 0 || len(result.Conflicts) != 0 {
+		t.Errorf("first import: %+v", result)
+	}
+	if _, err := target.GetRevision("demo", 1); err != nil {
+		t.Errorf("revision not imported: %v", err)
+	}
+
+	// 再次匯入相同內容不寫入任何記錄
+	result, err = Import(target, snapshot, ConflictFail, false)
+	must(t, err)
+	if result.Imported != 0 || result.Unchanged != 4 {
+		t.Errorf("second import: %+v", result)
+	}
+	if events, _ := target.GetEvents(""); len(events) != 1 {
+		t.Errorf("events duplicated: %d", len(events))
+	}
+}
+
+func TestImportConflicts(t *testing.T) {
+	snapshot := roundTrip(t, sampleStore(t))
+	snapshot.Containers[0].Status = model.StatusExited
+
+	tests := []struct {
+		policy  string
+		wantErr bool
+		status  model.ContainerStatus // 匯入後容器的狀態
+	}{
+		{policy: ConflictFail, wantErr: true, status: model.StatusRunning},
+		{policy: ConflictSkip, status: model.StatusRunning},
+		{policy: ConflictOverwrite, status: model.StatusExited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			target := sampleStore(t)
+			result, err := Import(target, snapshot, tt.policy, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if len(result.Conflicts) != 1 || result.Conflicts[0] != "container web" {
+				t.Errorf("conflicts: %v", result.Conflicts)
+			}
+			container, _ := target.GetContainer("web")
+			if container.Status != tt.status {
+				t.Errorf("container status %s, want %s", container.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestImportRevisionConflict(t *testing.T) {
+	snapshot := roundTrip(t, sampleStore(t))
+	snapshot.Revisions[0].Outcome = model.OutcomeFailed
+	snapshot.Projects[0].Services = []string{"web", "db"}
+
+	// 部署版本不可修改，overwrite 時仍不匯入任何記錄
+	target := sampleStore(t)
+	if _, err := Import(target, snapshot, ConflictOverwrite, false); err == nil {
+		t.Fatal("expected an error overwriting a revision")
+	}
+	if project, _ := target.GetProject("demo"); len(project.Services) != 1 {
+		t.Errorf("project written despite the blocked import: %+v", project)
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	snapshot := roundTrip(t, sampleStore(t))
+	target := NewMemory()
+	result, err := Import(target, snapshot, ConflictFail, true)
+	must(t, err)
+	if result.Imported != 4 {
+		t.Errorf("dry run reported %d imports, want 4", result.Imported)
+	}
+	if projects, _ := target.GetProjects(); len(projects) != 0 {
+		t.Errorf("dry run wrote %d projects", len(projects))
+	}
+}
+
+func TestImportInvalidPolicy(t *testing.T) {
+	if _, err := Import(NewMemory(), roundTrip(t, sampleStore(t)), "merge", false); err == nil {
+		t.Fatal("expected an error for an unknown policy")
+	}
+}
+
+// failingStore 讀取專案時回傳非 not found 的錯誤
+type failingStore struct {
+	*Memory
+}
+
+var errUnavailable = errors.New("storage unavailable")
+
+func (f failingStore) GetProject(name string) (*model.ProjectState, error) {
+	return nil, errUnavailable
+}
+
+func TestImportStoreError(t *testing.T) {
+	target := failingStore{NewMemory()}
+	result, err := Import(target, roundTrip(t, sampleStore(t)), ConflictOverwrite, false)
+	if !errors.Is(err, errUnavailable) {
+		t.Fatalf("got %v, want the store error", err)
+	}
+	if result.Imported != 0 {
+		t.Errorf("reported %d imports after a store error", result.Imported)
+	}
+	if containers, _ := target.GetContainers(); len(containers) != 0 {
+		t.Errorf("wrote %d containers after a store error", len(containers))
+	}
+}
+
+func TestReadSnapshotInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "not json", input: `version: 1`, want: "invalid snapshot"},
+		{name: "unknown field", input: `{"version": 1, "secrets": []}`, want: "unknown field"},
+		{name: "missing version", input: `{"projects": []}`, want: "missing version"},
+		{name: "newer version", input: `{"version": 99}`, want: "newer than the supported version"},
+		{name: "duplicate container", input: `{"version": 1, "containers": [{"name": "web"}, {"name": "web"}]}`, want: "duplicate container web"},
+		{name: "unknown status", input: `{"version": 1, "containers": [{"name": "web", "status": "paused"}]}`, want: `unknown status "paused"`},
+		{name: "revision without number", input: `{"version": 1, "revisions": [{"project": "demo"}]}`, want: "invalid number"},
+		{name: "event without type", input: `{"version": 1, "events": [{"project": "demo"}]}`, want: "has no type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSnapshot(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/vvvdwbvvv/rover/pkg/model"
+)
+
+// jsonState JSON 檔案的內容
+type jsonState struct {
+	Containers map[string]model.ContainerState `json:"containers"`
+	Projects   map[string]model.ProjectState   `json:"projects"`
+	Revisions  map[string][]model.Revision     `json:"revisions"`
+	Events     []model.Event                   `json:"events"`
+}
+
+// JSONFile 將狀態存成可直接閱讀的 JSON 檔案，方便除錯；每次修改都會重寫整個檔案
+type JSONFile struct {
+	*Memory
+	path string
+}
+
+// NewJSONFile 讀取 JSON 檔案；檔案不存在時從空狀態開始
+func NewJSONFile(path string) (*JSONFile, error) {
+	store := &JSONFile{Memory: NewMemory(), path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var state jsonState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for name, container := range state.Containers {
+		store.containers[name] = container
+	}
+	for name, project := range state.Projects {
+		store.projects[name] = project
+	}
+	for name, revisions := range state.Revisions {
+		store.revisions[name] = revisions
+	}
+	store.events = state.Events
+	return store, nil
+}
+
+// save 先寫入暫存檔再改名，避免中斷時留下損毀的檔案
+func (j *JSONFile) save() error {
+	j.mu.RLock()
+	data, err := json.MarshalIndent(jsonState{
+		Containers: j.containers,
+		Projects:   j.projects,
+		Revisions:  j.revisions,
+		Events:     j.events,
+	}, "", "  ")
+	j.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	return os.Rename(tmp, j.path)
+}
+
+// SaveContainer 存儲容器狀態
+func (j *JSONFile) SaveContainer(container model.ContainerState) error {
+	if err := j.Memory.SaveContainer(container); err != nil {
+		return err
+	}
+	return j.save()
+}
+
+// DeleteContainer 刪除容器
+func (j *JSONFile) DeleteContainer(name string) error {
+	if err := j.Memory.DeleteContainer(name); err != nil {
+		return err
+	}
+	return j.save()
+}
+
+// SaveProject 存儲專案狀態
+func (j *JSONFile) SaveProject(project model.ProjectState) error {
+	if err := j.Memory.SaveProject(project); err != nil {
+		return err
+	}
+	return j.save()
+}
+
+// DeleteProject 刪除專案
+func (j *JSONFile) DeleteProject(name string) error {
+	if err := j.Memory.DeleteProject(name); err != nil {
+		return err
+	}
+	return j.save()
+}
+
+// SaveRevision 存儲部署版本
+func (j *JSONFile) SaveRevision(revision *model.Revision) error {
+	if err := j.Memory.SaveRevision(revision); err != nil {
+		return err
+	}
+	return j.save()
+}
+
+// AddEvent 存儲事件
+func (j *JSONFile) AddEvent(event model.Event) error {
+	if err := j.Memory.AddEvent(event); err != nil {
+		return err
+	}
+	return j.save()
+}
+
+// SaveBatch 寫入所有記錄後重寫一次檔案；檔案以暫存檔改名寫入，不會只包含部分記錄。
+// 寫入檔案失敗時，記憶體中的狀態也回復為寫入前的內容
+func (j *JSONFile) SaveBatch(batch Batch) error {
+	j.mu.RLock()
+	previous := j.copyState()
+	j.mu.RUnlock()
+
+	if err := j.Memory.SaveBatch(batch); err != nil {
+		return err
+	}
+	if err := j.save(); err != nil {
+		j.mu.Lock()
+		j.setState(previous)
+		j.mu.Unlock()
+		return err
+	}
+	return nil
+}
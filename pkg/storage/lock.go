@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LockFileName 狀態目錄中的鎖定檔，內容為持有鎖定的程序 pid
+const LockFileName = "rover.pid"
+
+// lockRetryInterval 等待鎖定時的重試間隔
+const lockRetryInterval = 100 * time.Millisecond
+
+// LockError 另一個 rover 程序持有狀態目錄的鎖定
+type LockError struct {
+	Path string
+	PID  int
+}
+
+func (e *LockError) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("another rover process holds the lock on %s", e.Path)
+	}
+	return fmt.Sprintf("another rover process holds the lock (pid %d)", e.PID)
+}
+
+// FileLock 以 flock 實作的跨程序鎖定
+type FileLock struct {
+	file *os.File
+}
+
+// AcquireLock 取得 path 的獨佔鎖定；被其他程序持有時最多等待 timeout，逾時回傳 *LockError
+func AcquireLock(path string, timeout time.Duration) (*FileLock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			break
+		}
+		if !time.Now().Before(deadline) {
+			pid := readLockPID(path)
+			file.Close()
+			return nil, &LockError{Path: path, PID: pid}
+		}
+		time.Sleep(lockRetryInterval)
+	}
+
+	// 記錄 pid，讓其他程序能說明是誰持有鎖定
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &FileLock{file: file}, nil
+}
+
+// Release 釋放鎖定
+func (l *FileLock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	l.file.Truncate(0)
+	unlock(l.file)
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func readLockPID(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid
+}
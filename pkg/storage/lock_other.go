@@ -0,0 +1,14 @@
+//go:build !unix
+
+package storage
+
+import "os"
+
+// 非 Unix 平台不支援 flock，僅依賴 BoltDB 本身的檔案鎖定
+func tryLock(file *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlock(file *os.File) error {
+	return nil
+}
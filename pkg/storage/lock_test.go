@@ -0,0 +1,89 @@
+//go:build unix
+
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// flock 的鎖定屬於開啟的檔案，同一程序中兩次開啟同一個鎖定檔也會互斥
+
+func TestAcquireLockHeld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFileName)
+	lock, err := AcquireLock(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Release()
+
+	start := time.Now()
+	_, err = AcquireLock(path, 200*time.Millisecond)
+	var lockErr *LockError
+	if !errors.As(err, &lockErr) {
+		t.Fatalf("second acquire: got %v, want *LockError", err)
+	}
+	if lockErr.PID != os.Getpid() || lockErr.Path != path {
+		t.Errorf("lock error reports pid %d on %s, want pid %d on %s", lockErr.PID, lockErr.Path, os.Getpid(), path)
+	}
+	if waited := time.Since(start); waited < 200*time.Millisecond {
+		t.Errorf("second acquire gave up after %s, before the timeout", waited)
+	}
+}
+
+func TestAcquireLockAfterRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFileName)
+	lock, err := AcquireLock(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if pid := readLockPID(path); pid != 0 {
+		t.Errorf("released lock file still holds pid %d", pid)
+	}
+
+	again, err := AcquireLock(path, 0)
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	if err := again.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := again.Release(); err != nil {
+		t.Errorf("releasing twice failed: %v", err)
+	}
+}
+
+func TestAcquireLockWaits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFileName)
+	lock, err := AcquireLock(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	releasing := make(chan struct{})
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		close(releasing)
+		lock.Release()
+	}()
+
+	waiting, err := AcquireLock(path, 5*time.Second)
+	if err != nil {
+		t.Fatalf("acquire with a timeout: %v", err)
+	}
+	defer waiting.Release()
+	select {
+	case <-releasing:
+	default:
+		t.Error("lock acquired before the holder released it")
+	}
+	if pid := readLockPID(path); pid != os.Getpid() {
+		t.Errorf("lock file holds pid %d, want %d", pid, os.Getpid())
+	}
+}
@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/vvvdwbvvv/rover/pkg/model"
+)
+
+// Memory 將狀態保存在記憶體中，供測試使用；程序結束後狀態即消失。
+// 存取時皆複製記錄，呼叫端修改取得的記錄不會影響已存儲的狀態，與 BoltDB 後端的行為一致
+type Memory struct {
+	mu         sync.RWMutex
+	containers map[string]model.ContainerState
+	projects   map[string]model.ProjectState
+	revisions  map[string][]model.Revision
+	events     []model.Event
+}
+
+// NewMemory 建立空的 Memory
+func NewMemory() *Memory {
+	return &Memory{
+		containers: make(map[string]model.ContainerState),
+		projects:   make(map[string]model.ProjectState),
+		revisions:  make(map[string][]model.Revision),
+	}
+}
+
+// SaveContainer 存儲容器狀態
+func (m *Memory) SaveContainer(container model.ContainerState) error {
+	if container.Name == "" {
+		return errors.New("container name cannot be empty")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.containers[container.Name] = clone(container)
+	return nil
+}
+
+// GetContainer 取得單個容器
+func (m *Memory) GetContainer(name string) (*model.ContainerState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	container, ok := m.containers[name]
+	if !ok {
+		return nil, ErrContainerNotFound
+	}
+	container = clone(container)
+	return &container, nil
+}
+
+// GetContainers 依名稱排序取得所有容器
+func (m *Memory) GetContainers() ([]model.ContainerState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var containers []model.ContainerState
+	for _, container := range m.containers {
+		containers = append(containers, clone(container))
+	}
+	sort.Slice(containers, func(i, j int) bool { return containers[i].Name < containers[j].Name })
+	return containers, nil
+}
+
+// DeleteContainer 刪除容器
+func (m *Memory) DeleteContainer(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.containers, name)
+	return nil
+}
+
+// SaveProject 存儲專案狀態
+func (m *Memory) SaveProject(project model.ProjectState) error {
+	if project.Name == "" {
+		return errors.New("project name cannot be empty")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.projects[project.Name] = clone(project)
+	return nil
+}
+
+// GetProject 取得單個專案
+func (m *Memory) GetProject(name string) (*model.ProjectState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	project, ok := m.projects[name]
+	if !ok {
+		return nil, ErrProjectNotFound
+	}
+	project = clone(project)
+	return &project, nil
+}
+
+// GetProjects 依名稱排序取得所有專案
+func (m *Memory) GetProjects() ([]model.ProjectState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var projects []model.ProjectState
+	for _, project := range m.projects {
+		projects = append(projects, clone(project))
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+	return projects, nil
+}
+
+// DeleteProject 刪除專案
+func (m *Memory) DeleteProject(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.projects, name)
+	return nil
+}
+
+// SaveRevision 存儲部署版本；Number 為 0 時指派下一個版本號，既有的版本不可覆寫
+func (m *Memory) SaveRevision(revision *model.Revision) error {
+	if revision.Project == "" {
+		return errors.New("revision project cannot be empty")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	revisions, err := appendRevision(m.revisions[revision.Project], revision)
+	if err != nil {
+		return err
+	}
+	m.revisions[revision.Project] = revisions
+	return nil
+}
+
+// appendRevision 將版本加入依版本號排序的 revisions；Number 為 0 時指派下一個版本號
+func appendRevision(revisions []model.Revision, revision *model.Revision) ([]model.Revision, error) {
+	if revision.Number == 0 {
+		revision.Number = 1
+		if n := len(revisions); n > 0 {
+			revision.Number = revisions[n-1].Number + 1
+		}
+	}
+	for i := range revisions {
+		if revisions[i].Number == revision.Number {
+			return nil, fmt.Errorf("revision %d of project %s: %w", revision.Number, revision.Project, ErrRevisionExists)
+		}
+	}
+	revisions = append(revisions, clone(*revision))
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Number < revisions[j].Number })
+	return revisions, nil
+}
+
+// GetRevision 取得專案的指定版本
+func (m *Memory) GetRevision(project string, number int) (*model.Revision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, revision := range m.revisions[project] {
+		if revision.Number == number {
+			revision = clone(revision)
+			return &revision, nil
+		}
+	}
+	return nil, ErrRevisionNotFound
+}
+
+// GetRevisions 依版本號排序取得專案的所有版本；project 為空字串時回傳所有專案的版本
+func (m *Memory) GetRevisions(project string) ([]model.Revision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if project != "" {
+		return clone(m.revisions[project]), nil
+	}
+
+	projects := make([]string, 0, len(m.revisions))
+	for name := range m.revisions {
+		projects = append(projects, name)
+	}
+	sort.Strings(projects)
+	var revisions []model.Revision
+	for _, name := range projects {
+		revisions = append(revisions, clone(m.revisions[name])...)
+	}
+	return revisions, nil
+}
+
+// AddEvent 依序存儲事件
+func (m *Memory) AddEvent(event model.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	event.ID = uint64(len(m.events)) + 1
+	m.events = append(m.events, event)
+	return nil
+}
+
+// GetEvents 依發生順序取得事件；project 為空字串時回傳所有事件
+func (m *Memory) GetEvents(project string) ([]model.Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var events []model.Event
+	for _, event := range m.events {
+		if project == "" || event.Project == project {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// SaveBatch 先在狀態的副本上寫入所有記錄，全部成功後才取代目前的狀態
+func (m *Memory) SaveBatch(batch Batch) error {
+	if err := batch.validate(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	staged := m.copyState()
+	for _, project := range batch.Projects {
+		staged.projects[project.Name] = clone(project)
+	}
+	for _, container := range batch.Containers {
+		staged.containers[container.Name] = clone(container)
+	}
+	for i := range batch.Revisions {
+		revision := &batch.Revisions[i]
+		revisions, err := appendRevision(staged.revisions[revision.Project], revision)
+		if err != nil {
+			return err
+		}
+		staged.revisions[revision.Project] = revisions
+	}
+	for _, event := range batch.Events {
+		event.ID = uint64(len(staged.events)) + 1
+		staged.events = append(staged.events, event)
+	}
+	m.setState(staged)
+	return nil
+}
+
+// memoryState Memory 內容的副本，呼叫端須持有鎖定
+type memoryState struct {
+	containers map[string]model.ContainerState
+	projects   map[string]model.ProjectState
+	revisions  map[string][]model.Revision
+	events     []model.Event
+}
+
+// copyState 複製目前的狀態；記錄存入時已複製且不會被修改，只需複製 map 與 slice
+func (m *Memory) copyState() memoryState {
+	state := memoryState{
+		containers: make(map[string]model.ContainerState, len(m.containers)),
+		projects:   make(map[string]model.ProjectState, len(m.projects)),
+		revisions:  make(map[string][]model.Revision, len(m.revisions)),
+		events:     append([]model.Event(nil), m.events...),
+	}
+	for name, container := range m.containers {
+		state.containers[name] = container
+	}
+	for name, project := range m.projects {
+		state.projects[name] = project
+	}
+	for name, revisions := range m.revisions {
+		state.revisions[name] = append([]model.Revision(nil), revisions...)
+	}
+	return state
+}
+
+// setState 以 state 取代目前的狀態
+func (m *Memory) setState(state memoryState) {
+	m.containers = state.containers
+	m.projects = state.projects
+	m.revisions = state.revisions
+	m.events = state.events
+}
+
+// Close Memory 不需要釋放資源
+func (m *Memory) Close() error {
+	return nil
+}
+
+// clone 以 JSON 來回轉換複製記錄，slice 與 json.RawMessage 不再與原本的記錄共用
+func clone[T any](v T) T {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var copied T
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return v
+	}
+	return copied
+}
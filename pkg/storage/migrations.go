@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+var (
+	// metaBucket 存放 schema 版本等中繼資料
+	metaBucket       = []byte("meta")
+	schemaVersionKey = []byte("schema_version")
+)
+
+// Migration 將資料庫從 Version-1 升級到 Version
+type Migration struct {
+	Version     int
+	Description string
+	apply       func(tx *bbolt.Tx) error
+}
+
+// migrations 依版本排序；新增 schema 變更時在最後加上一筆，不可修改既有的項目。
+// 版本 1 為沒有 meta bucket 的舊資料庫，只有 containers bucket
+var migrations = []Migration{
+	{
+		Version:     2,
+		Description: "add projects, revisions and events buckets",
+		apply: func(tx *bbolt.Tx) error {
+			for _, name := range [][]byte{containerBucket, projectBucket, revisionBucket, eventBucket} {
+				if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     3,
+		Description: "backfill service and replica of containers, drop placeholder IDs",
+		apply: func(tx *bbolt.Tx) error {
+			bucket := tx.Bucket(containerBucket)
+			// migration 以原始 JSON 操作，不依賴之後可能變更的 model 結構
+			updated := make(map[string][]byte)
+			err := bucket.ForEach(func(k, v []byte) error {
+				var container map[string]interface{}
+				if err := json.Unmarshal(v, &container); err != nil {
+					return fmt.Errorf("failed to unmarshal container %s: %w", k, err)
+				}
+				name, _ := container["name"].(string)
+				if _, ok := container["service"]; !ok {
+					container["service"] = name
+					container["replica"] = 1
+				}
+				// 舊版以容器名稱充當 ID
+				if id, _ := container["id"].(string); id == name {
+					container["id"] = ""
+				}
+				data, err := json.Marshal(container)
+				if err != nil {
+					return err
+				}
+				updated[string(k)] = data
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			for k, v := range updated {
+				if err := bucket.Put([]byte(k), v); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// SchemaVersion 回傳目前程式支援的最新 schema 版本
+func SchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// readSchemaVersion 讀取資料庫的 schema 版本：沒有任何 bucket 為新的資料庫（回傳 0），
+// 有資料但沒有 meta bucket 為版本 1
+func readSchemaVersion(tx *bbolt.Tx) (int, error) {
+	if bucket := tx.Bucket(metaBucket); bucket != nil {
+		value := bucket.Get(schemaVersionKey)
+		if value == nil {
+			return 0, fmt.Errorf("meta bucket has no schema version")
+		}
+		version, err := strconv.Atoi(string(value))
+		if err != nil {
+			return 0, fmt.Errorf("invalid schema version %q: %w", value, err)
+		}
+		return version, nil
+	}
+
+	empty := true
+	err := tx.ForEach(func(name []byte, _ *bbolt.Bucket) error {
+		empty = false
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if empty {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+func writeSchemaVersion(tx *bbolt.Tx, version int) error {
+	bucket, err := tx.CreateBucketIfNotExists(metaBucket)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(schemaVersionKey, []byte(strconv.Itoa(version)))
+}
+
+// pendingMigrations 回傳從 version 升級到最新版本需要執行的 migration
+func pendingMigrations(version int) []Migration {
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// migrate 依序執行尚未套用的 migration；既有的資料庫會先備份到 dbPath.v<版本>-<時間>.bak。
+// 回傳原本的版本與備份路徑，沒有備份時路徑為空字串
+func migrate(db *bbolt.DB, dbPath string) (int, string, error) {
+	var version int
+	if err := db.View(func(tx *bbolt.Tx) (err error) {
+		version, err = readSchemaVersion(tx)
+		return err
+	}); err != nil {
+		return 0, "", fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	latest := SchemaVersion()
+	if version > latest {
+		return version, "", fmt.Errorf("database schema version %d is newer than the supported version %d; upgrade rover", version, latest)
+	}
+	if version == latest {
+		return version, "", nil
+	}
+
+	// 新的資料庫直接建立最新的 schema
+	if version == 0 {
+		err := db.Update(func(tx *bbolt.Tx) error {
+			for _, m := range migrations {
+				if err := m.apply(tx); err != nil {
+					return err
+				}
+			}
+			return writeSchemaVersion(tx, latest)
+		})
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to initialize schema: %w", err)
+		}
+		return 0, "", nil
+	}
+
+	backup := fmt.Sprintf("%s.v%d-%s.bak", dbPath, version, time.Now().Format("20060102150405"))
+	if err := db.View(func(tx *bbolt.Tx) error {
+		return tx.CopyFile(backup, 0600)
+	}); err != nil {
+		return version, "", fmt.Errorf("failed to back up database to %s: %w", backup, err)
+	}
+
+	// 每個 migration 在各自的 transaction 中執行並更新版本，失敗時停在上一個成功的版本
+	for _, m := range pendingMigrations(version) {
+		err := db.Update(func(tx *bbolt.Tx) error {
+			if err := m.apply(tx); err != nil {
+				return err
+			}
+			return writeSchemaVersion(tx, m.Version)
+		})
+		if err != nil {
+			return version, backup, fmt.Errorf("migration to version %d (%s) failed, backup at %s: %w", m.Version, m.Description, backup, err)
+		}
+	}
+	return version, backup, nil
+}
+
+// InspectBoltDB 以唯讀模式開啟資料庫，回傳目前的 schema 版本與尚未套用的 migration；空的資料庫版本為 0
+func InspectBoltDB(dbPath string) (int, []Migration, error) {
+	db, err := bbolt.Open(dbPath, 0600, &bbolt.Options{ReadOnly: true, Timeout: 1 * time.Second})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	var version int
+	if err := db.View(func(tx *bbolt.Tx) (err error) {
+		version, err = readSchemaVersion(tx)
+		return err
+	}); err != nil {
+		return 0, nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	// 沒有任何 bucket 的資料庫（版本 0）在下次開啟時套用所有 migration 建立 schema
+	return version, pendingMigrations(version), nil
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.etcd.io/bbolt"
+)
+
+// writeBoltDB 以 setup 建立測試用的資料庫檔案
+func writeBoltDB(t *testing.T, setup func(tx *bbolt.Tx) error) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rover.db")
+	db, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Update(setup); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMigrateNewDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rover.db")
+	store, err := NewBoltDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from, backup := store.Migrated(); from != 0 || backup != "" {
+		t.Errorf("new database reported migration from %d with backup %q", from, backup)
+	}
+	store.Close()
+
+	version, pending, err := InspectBoltDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != SchemaVersion() || len(pending) != 0 {
+		t.Errorf("new database at version %d with %d pending migrations, want %d and none", version, len(pending), SchemaVersion())
+	}
+}
+
+func TestInspectEmptyDatabase(t *testing.T) {
+	// 已存在但沒有任何 bucket 的資料庫：版本 0，開啟時套用所有 migration
+	path := writeBoltDB(t, func(tx *bbolt.Tx) error { return nil })
+
+	version, pending, err := InspectBoltDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0 || len(pending) != len(migrations) {
+		t.Fatalf("got version %d with %d pending migrations, want 0 with %d", version, len(pending), len(migrations))
+	}
+
+	store, err := NewBoltDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from, backup := store.Migrated(); from != 0 || backup != "" {
+		t.Errorf("empty database migrated from %d with backup %q, want 0 and no backup", from, backup)
+	}
+	store.Close()
+
+	if version, pending, _ := InspectBoltDB(path); version != SchemaVersion() || len(pending) != 0 {
+		t.Errorf("after opening: version %d with %d pending migrations, want %d and none", version, len(pending), SchemaVersion())
+	}
+}
+
+func TestMigrateVersion1(t *testing.T) {
+	// 版本 1：只有 containers bucket，容器沒有 service 與 replica，ID 為容器名稱
+	path := writeBoltDB(t, func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(containerBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte("web"), []byte(`{"id":"web","name":"web","image":"nginx","status":"running"}`))
+	})
+
+	version, pending, err := InspectBoltDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 1 || len(pending) != SchemaVersion()-1 {
+		t.Fatalf("got version %d with %d pending migrations, want 1 with %d", version, len(pending), SchemaVersion()-1)
+	}
+
+	store, err := NewBoltDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	from, backup := store.Migrated()
+	if from != 1 {
+		t.Errorf("migrated from version %d, want 1", from)
+	}
+	if _, err := os.Stat(backup); err != nil {
+		t.Errorf("backup %q not written: %v", backup, err)
+	}
+
+	container, err := store.GetContainer("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if container.Service != "web" || container.Replica != 1 {
+		t.Errorf("service and replica not backfilled: %+v", container)
+	}
+	if container.ID != "" {
+		t.Errorf("placeholder ID %q not dropped", container.ID)
+	}
+	if _, err := store.GetProjects(); err != nil {
+		t.Errorf("projects bucket missing after migration: %v", err)
+	}
+	if _, err := store.GetRevisions(""); err != nil {
+		t.Errorf("revisions bucket missing after migration: %v", err)
+	}
+}
+
+func TestMigrateNewerVersion(t *testing.T) {
+	path := writeBoltDB(t, func(tx *bbolt.Tx) error {
+		return writeSchemaVersion(tx, SchemaVersion()+1)
+	})
+	if _, err := NewBoltDB(path); err == nil {
+		t.Fatal("expected an error opening a database with a newer schema")
+	}
+}
+
+func TestMigrateInvalidVersion(t *testing.T) {
+	path := writeBoltDB(t, func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(metaBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(schemaVersionKey, []byte("two"))
+	})
+	if _, _, err := InspectBoltDB(path); err == nil {
+		t.Fatal("expected an error for an invalid schema version")
+	}
+}
+
+func TestMigrationsOrdered(t *testing.T) {
+	for i, m := range migrations {
+		if want := i + 2; m.Version != want {
+			t.Errorf("migration %d has version %d, want %d", i, m.Version, want)
+		}
+		if m.Description == "" {
+			t.Errorf("migration to version %d has no description", m.Version)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/vvvdwbvvv/rover/pkg/model"
+)
+
+// 可用的儲存後端
+const (
+	BackendBolt   = "bolt"
+	BackendMemory = "memory"
+	BackendJSON   = "json"
+)
+
+// BackendEnv 選擇儲存後端的環境變數
+const BackendEnv = "ROVER_STORAGE"
+
+// ErrContainerNotFound 找不到指定的容器記錄
+var ErrContainerNotFound = errors.New("container not found")
+
+// ErrProjectNotFound 找不到指定的專案記錄
+var ErrProjectNotFound = errors.New("project not found")
+
+// ErrRevisionNotFound 找不到指定的部署版本
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// ErrRevisionExists 部署版本存儲後不可修改
+var ErrRevisionExists = errors.New("revision already exists")
+
+// Store 定義 Rover 狀態的存取方式
+type Store interface {
+	SaveContainer(container model.ContainerState) error
+	// GetContainer 取得容器記錄，不存在時回傳 ErrContainerNotFound
+	GetContainer(name string) (*model.ContainerState, error)
+	GetContainers() ([]model.ContainerState, error)
+	DeleteContainer(name string) error
+
+	SaveProject(project model.ProjectState) error
+	// GetProject 取得專案記錄，不存在時回傳 ErrProjectNotFound
+	GetProject(name string) (*model.ProjectState, error)
+	GetProjects() ([]model.ProjectState, error)
+	DeleteProject(name string) error
+
+	// SaveRevision 存儲部署版本；Number 為 0 時指派該專案的下一個版本號，
+	// 版本已存在時回傳 ErrRevisionExists
+	SaveRevision(revision *model.Revision) error
+	// GetRevision 取得部署版本，不存在時回傳 ErrRevisionNotFound
+	GetRevision(project string, number int) (*model.Revision, error)
+	// GetRevisions 依版本號排序回傳專案的所有部署版本；project 為空字串時依專案名稱排序回傳所有版本
+	GetRevisions(project string) ([]model.Revision, error)
+
+	// AddEvent 依序存儲事件並指派 ID
+	AddEvent(event model.Event) error
+	// GetEvents 依發生順序回傳事件；project 為空字串時回傳所有事件
+	GetEvents(project string) ([]model.Event, error)
+
+	// SaveBatch 一次寫入多筆記錄，任何一筆失敗時不寫入任何記錄；
+	// 各記錄的規則與 SaveProject、SaveContainer、SaveRevision 及 AddEvent 相同
+	SaveBatch(batch Batch) error
+
+	Close() error
+}
+
+// Batch 以 SaveBatch 一次寫入的記錄
+type Batch struct {
+	Projects   []model.ProjectState
+	Containers []model.ContainerState
+	Revisions  []model.Revision
+	Events     []model.Event
+}
+
+// validate 檢查必要欄位，避免寫入到一半才失敗
+func (b Batch) validate() error {
+	for _, project := range b.Projects {
+		if project.Name == "" {
+			return errors.New("project name cannot be empty")
+		}
+	}
+	for _, container := range b.Containers {
+		if container.Name == "" {
+			return errors.New("container name cannot be empty")
+		}
+	}
+	for _, revision := range b.Revisions {
+		if revision.Project == "" {
+			return errors.New("revision project cannot be empty")
+		}
+	}
+	return nil
+}
+
+// StateDirEnv 指定狀態目錄的環境變數
+const StateDirEnv = "ROVER_STATE_DIR"
+
+// Config 儲存後端的設定
+type Config struct {
+	Backend     string        // bolt、memory 或 json，預設為 bolt
+	Dir         string        // 狀態目錄，預設為 DefaultStateDir()
+	Path        string        // bolt 與 json 後端的檔案路徑，預設為狀態目錄下的 DefaultPath(Backend)
+	LockTimeout time.Duration // 其他 rover 程序持有鎖定時的等待時間
+}
+
+// DefaultStateDir 回傳預設的狀態目錄：ROVER_STATE_DIR，其次為 $XDG_STATE_HOME/rover，再其次為 ~/.local/state/rover
+func DefaultStateDir() (string, error) {
+	if dir := os.Getenv(StateDirEnv); dir != "" {
+		return dir, nil
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "rover"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine state directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "rover"), nil
+}
+
+// ResolvePath 回傳設定實際使用的狀態目錄與檔案路徑
+func (c Config) ResolvePath() (string, string, error) {
+	dir := c.Dir
+	if dir == "" {
+		var err error
+		if dir, err = DefaultStateDir(); err != nil {
+			return "", "", err
+		}
+	}
+	path := c.Path
+	if path == "" {
+		path = filepath.Join(dir, DefaultPath(c.Backend))
+	}
+	return dir, path, nil
+}
+
+// DefaultPath 回傳後端預設的檔案名稱
+func DefaultPath(backend string) string {
+	if backend == BackendJSON {
+		return "rover.json"
+	}
+	return "rover.db"
+}
+
+// BackendFromEnv 回傳 ROVER_STORAGE 指定的後端，未設定時為 bolt
+func BackendFromEnv() string {
+	if backend := os.Getenv(BackendEnv); backend != "" {
+		return backend
+	}
+	return BackendBolt
+}
+
+// Open 依設定開啟儲存後端；bolt 與 json 後端會先取得狀態目錄的鎖定，直到 Close 才釋放
+func Open(config Config) (Store, error) {
+	if config.Backend == "" {
+		config.Backend = BackendBolt
+	}
+
+	switch config.Backend {
+	case BackendMemory:
+		return NewMemory(), nil
+	case BackendBolt, BackendJSON:
+	default:
+		return nil, fmt.Errorf("unsupported storage backend %q (use %s, %s or %s)", config.Backend, BackendBolt, BackendMemory, BackendJSON)
+	}
+
+	dir, path, err := config.ResolvePath()
+	if err != nil {
+		return nil, err
+	}
+	lock, err := LockStateDir(dir, config.LockTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var store Store
+	if config.Backend == BackendBolt {
+		store, err = NewBoltDB(path)
+	} else {
+		store, err = NewJSONFile(path)
+	}
+	if err != nil {
+		lock.Release()
+		return nil, err
+	}
+	return &lockedStore{Store: store, lock: lock}, nil
+}
+
+// LockStateDir 建立狀態目錄並取得其鎖定
+func LockStateDir(dir string, timeout time.Duration) (*FileLock, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return AcquireLock(filepath.Join(dir, LockFileName), timeout)
+}
+
+// lockedStore 在 Close 時一併釋放狀態目錄的鎖定
+type lockedStore struct {
+	Store
+	lock *FileLock
+}
+
+func (s *lockedStore) Close() error {
+	err := s.Store.Close()
+	s.lock.Release()
+	return err
+}
+
+// 確認各後端都實作 Store
+var (
+	_ Store = (*BoltDB)(nil)
+	_ Store = (*Memory)(nil)
+	_ Store = (*JSONFile)(nil)
+)
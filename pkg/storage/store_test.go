@@ -0,0 +1,393 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vvvdwbvvv/rover/pkg/model"
+)
+
+// backends 建立各後端的空 store；reopen 重新開啟同一份資料，memory 後端沒有持久化時為 nil
+var backends = []struct {
+	name string
+	open func(t *testing.T) (store Store, reopen func() Store)
+}{
+	{
+		name: BackendMemory,
+		open: func(t *testing.T) (Store, func() Store) {
+			return NewMemory(), nil
+		},
+	},
+	{
+		name: BackendJSON,
+		open: func(t *testing.T) (Store, func() Store) {
+			path := filepath.Join(t.TempDir(), "rover.json")
+			reopen := func() Store {
+				store, err := NewJSONFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return store
+			}
+			return reopen(), reopen
+		},
+	},
+	{
+		name: BackendBolt,
+		open: func(t *testing.T) (Store, func() Store) {
+			path := filepath.Join(t.TempDir(), "rover.db")
+			reopen := func() Store {
+				store, err := NewBoltDB(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return store
+			}
+			return reopen(), reopen
+		},
+	},
+}
+
+// forEachBackend 對每個後端執行相同的測試
+func forEachBackend(t *testing.T, test func(t *testing.T, store Store, reopen func() Store)) {
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			store, reopen := backend.open(t)
+			defer func() { store.Close() }()
+			var reopenStore func() Store
+			if reopen != nil {
+				reopenStore = func() Store {
+					store.Close()
+					store = reopen()
+					return store
+				}
+			}
+			test(t, store, reopenStore)
+		})
+	}
+}
+
+func TestStoreContainers(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, reopen func() Store) {
+		if _, err := store.GetContainer("web"); !errors.Is(err, ErrContainerNotFound) {
+			t.Fatalf("missing container: got %v, want ErrContainerNotFound", err)
+		}
+		if err := store.SaveContainer(model.ContainerState{}); err == nil {
+			t.Error("expected an error saving a container without a name")
+		}
+
+		for _, name := range []string{"web", "db"} {
+			if err := store.SaveContainer(model.ContainerState{Name: name, Project: "demo", Status: model.StatusRunning}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := store.SaveContainer(model.ContainerState{Name: "web", Project: "demo", Status: model.StatusExited}); err != nil {
+			t.Fatal(err)
+		}
+
+		container, err := store.GetContainer("web")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if container.Status != model.StatusExited {
+			t.Errorf("saving again did not replace the container: %+v", container)
+		}
+		if containers, _ := store.GetContainers(); len(containers) != 2 {
+			t.Errorf("got %d containers, want 2", len(containers))
+		}
+
+		if err := store.DeleteContainer("db"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.GetContainer("db"); !errors.Is(err, ErrContainerNotFound) {
+			t.Errorf("deleted container: got %v, want ErrContainerNotFound", err)
+		}
+		if err := store.DeleteContainer("missing"); err != nil {
+			t.Errorf("deleting a missing container failed: %v", err)
+		}
+	})
+}
+
+func TestStoreProjects(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, reopen func() Store) {
+		if _, err := store.GetProject("demo"); !errors.Is(err, ErrProjectNotFound) {
+			t.Fatalf("missing project: got %v, want ErrProjectNotFound", err)
+		}
+		if err := store.SaveProject(model.ProjectState{}); err == nil {
+			t.Error("expected an error saving a project without a name")
+		}
+
+		for _, name := range []string{"web-app", "api"} {
+			if err := store.SaveProject(model.ProjectState{Name: name, Services: []string{"web"}}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		projects, err := store.GetProjects()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(projects) != 2 || projects[0].Name != "api" || projects[1].Name != "web-app" {
+			t.Errorf("projects not sorted by name: %+v", projects)
+		}
+
+		if err := store.DeleteProject("api"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.GetProject("api"); !errors.Is(err, ErrProjectNotFound) {
+			t.Errorf("deleted project: got %v, want ErrProjectNotFound", err)
+		}
+	})
+}
+
+func TestStoreRevisions(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, reopen func() Store) {
+		if _, err := store.GetRevision("demo", 1); !errors.Is(err, ErrRevisionNotFound) {
+			t.Fatalf("missing revision: got %v, want ErrRevisionNotFound", err)
+		}
+
+		first := &model.Revision{Project: "demo", Outcome: model.OutcomeSucceeded}
+		if err := store.SaveRevision(first); err != nil {
+			t.Fatal(err)
+		}
+		if first.Number != 1 {
+			t.Errorf("first revision got number %d, want 1", first.Number)
+		}
+		if err := store.SaveRevision(&model.Revision{Project: "demo", Number: 3}); err != nil {
+			t.Fatal(err)
+		}
+		next := &model.Revision{Project: "demo"}
+		if err := store.SaveRevision(next); err != nil {
+			t.Fatal(err)
+		}
+		if next.Number != 4 {
+			t.Errorf("next revision got number %d, want 4", next.Number)
+		}
+		if err := store.SaveRevision(&model.Revision{Project: "other"}); err != nil {
+			t.Fatal(err)
+		}
+
+		err := store.SaveRevision(&model.Revision{Project: "demo", Number: 1, Outcome: model.OutcomeFailed})
+		if !errors.Is(err, ErrRevisionExists) {
+			t.Errorf("overwriting a revision: got %v, want ErrRevisionExists", err)
+		}
+		if revision, _ := store.GetRevision("demo", 1); revision == nil || revision.Outcome != model.OutcomeSucceeded {
+			t.Errorf("revision 1 was modified: %+v", revision)
+		}
+
+		revisions, err := store.GetRevisions("demo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if numbers := revisionNumbers(revisions); len(numbers) != 3 || numbers[0] != 1 || numbers[1] != 3 || numbers[2] != 4 {
+			t.Errorf("revisions of demo: got %v, want [1 3 4]", numbers)
+		}
+		all, err := store.GetRevisions("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != 4 || all[0].Project != "demo" || all[3].Project != "other" {
+			t.Errorf("all revisions not grouped by project: %+v", all)
+		}
+	})
+}
+
+func TestStoreEvents(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, reopen func() Store) {
+		now := time.Now()
+		for _, event := range []model.Event{
+			{Project: "demo", Container: "web", Type: model.EventDeployed, CreatedAt: now},
+			{Project: "other", Container: "db", Type: model.EventStopped, CreatedAt: now},
+			{Project: "demo", Container: "web", Type: model.EventStopped, CreatedAt: now},
+		} {
+			if err := store.AddEvent(event); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		events, err := store.GetEvents("demo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 2 || events[0].Type != model.EventDeployed || events[1].Type != model.EventStopped {
+			t.Errorf("events of demo not in order: %+v", events)
+		}
+		all, _ := store.GetEvents("")
+		if len(all) != 3 {
+			t.Fatalf("got %d events, want 3", len(all))
+		}
+		for i, event := range all {
+			if event.ID != uint64(i+1) {
+				t.Errorf("event %d has ID %d, want %d", i, event.ID, i+1)
+			}
+		}
+	})
+}
+
+// TestStoreSaveBatch 批次寫入全部成功，或在任何一筆失敗時不寫入任何記錄
+func TestStoreSaveBatch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, reopen func() Store) {
+		if err := store.SaveRevision(&model.Revision{Project: "demo"}); err != nil {
+			t.Fatal(err)
+		}
+
+		failing := Batch{
+			Projects:   []model.ProjectState{{Name: "demo"}},
+			Containers: []model.ContainerState{{Name: "web", Project: "demo"}},
+			Revisions:  []model.Revision{{Project: "demo", Number: 2}, {Project: "demo", Number: 1}},
+			Events:     []model.Event{{Project: "demo", Type: model.EventDeployed}},
+		}
+		if err := store.SaveBatch(failing); !errors.Is(err, ErrRevisionExists) {
+			t.Fatalf("batch with an existing revision: got %v, want ErrRevisionExists", err)
+		}
+		if err := store.SaveBatch(Batch{Containers: []model.ContainerState{{Name: "web"}, {}}}); err == nil {
+			t.Error("expected an error saving a batch with a container without a name")
+		}
+
+		check := func(store Store) {
+			if _, err := store.GetProject("demo"); !errors.Is(err, ErrProjectNotFound) {
+				t.Errorf("project of a failed batch was written: %v", err)
+			}
+			if _, err := store.GetContainer("web"); !errors.Is(err, ErrContainerNotFound) {
+				t.Errorf("container of a failed batch was written: %v", err)
+			}
+			if revisions, _ := store.GetRevisions("demo"); len(revisions) != 1 {
+				t.Errorf("got %d revisions after a failed batch, want 1", len(revisions))
+			}
+			if events, _ := store.GetEvents(""); len(events) != 0 {
+				t.Errorf("events of a failed batch were written: %+v", events)
+			}
+		}
+		check(store)
+		if reopen != nil {
+			store = reopen()
+			check(store)
+		}
+
+		batch := failing
+		batch.Revisions = []model.Revision{{Project: "demo", Number: 2}, {Project: "other"}}
+		if err := store.SaveBatch(batch); err != nil {
+			t.Fatal(err)
+		}
+		if reopen != nil {
+			store = reopen()
+		}
+		if _, err := store.GetContainer("web"); err != nil {
+			t.Errorf("container of the batch missing: %v", err)
+		}
+		if revisions, _ := store.GetRevisions(""); len(revisions) != 3 {
+			t.Errorf("got %d revisions, want 3", len(revisions))
+		}
+		if events, _ := store.GetEvents("demo"); len(events) != 1 || events[0].ID != 1 {
+			t.Errorf("batch events: %+v", events)
+		}
+	})
+}
+
+// TestStoreReturnsCopies 修改取得的記錄不可改變已存儲的狀態
+func TestStoreReturnsCopies(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, reopen func() Store) {
+		container := model.ContainerState{
+			Name:     "web",
+			Project:  "demo",
+			Profiles: []string{"debug"},
+			Ports:    []model.PortBinding{{HostPort: 8080, ContainerPort: 80, Protocol: "tcp"}},
+			History:  []model.StatusChange{{To: model.StatusRunning}},
+		}
+		if err := store.SaveContainer(container); err != nil {
+			t.Fatal(err)
+		}
+		container.Profiles[0] = "saved-then-changed"
+		got, err := store.GetContainer("web")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got.Profiles[0] = "changed"
+		got.Ports[0].HostPort = 9090
+		got.History[0].To = model.StatusExited
+		if containers, _ := store.GetContainers(); len(containers) == 1 {
+			containers[0].Profiles[0] = "changed"
+		}
+		got, _ = store.GetContainer("web")
+		if got.Profiles[0] != "debug" || got.Ports[0].HostPort != 8080 || got.History[0].To != model.StatusRunning {
+			t.Errorf("stored container was modified through a returned copy: %+v", got)
+		}
+
+		if err := store.SaveProject(model.ProjectState{Name: "demo", Services: []string{"web"}}); err != nil {
+			t.Fatal(err)
+		}
+		project, _ := store.GetProject("demo")
+		project.Services[0] = "changed"
+		if projects, _ := store.GetProjects(); len(projects) == 1 {
+			projects[0].Services[0] = "changed"
+		}
+		if project, _ := store.GetProject("demo"); project.Services[0] != "web" {
+			t.Errorf("stored project was modified through a returned copy: %+v", project)
+		}
+
+		revision := &model.Revision{
+			Project:  "demo",
+			Services: []model.ServiceRevision{{Name: "web", ConfigHash: "abc"}},
+			Compose:  json.RawMessage(`{"name":"demo"}`),
+		}
+		if err := store.SaveRevision(revision); err != nil {
+			t.Fatal(err)
+		}
+		revision.Services[0].ConfigHash = "saved-then-changed"
+		stored, _ := store.GetRevision("demo", 1)
+		stored.Services[0].ConfigHash = "changed"
+		stored.Compose[2] = 'X'
+		if revisions, _ := store.GetRevisions("demo"); len(revisions) == 1 {
+			revisions[0].Services[0].ConfigHash = "changed"
+		}
+		if revisions, _ := store.GetRevisions(""); len(revisions) == 1 {
+			revisions[0].Services[0].ConfigHash = "changed"
+		}
+		stored, _ = store.GetRevision("demo", 1)
+		if stored.Services[0].ConfigHash != "abc" || string(stored.Compose) != `{"name":"demo"}` {
+			t.Errorf("stored revision was modified through a returned copy: %+v", stored)
+		}
+	})
+}
+
+func TestStorePersistence(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, reopen func() Store) {
+		if reopen == nil {
+			t.Skip("backend does not persist")
+		}
+		store.SaveContainer(model.ContainerState{Name: "web", Project: "demo"})
+		store.SaveProject(model.ProjectState{Name: "demo"})
+		store.SaveRevision(&model.Revision{Project: "demo"})
+		store.AddEvent(model.Event{Project: "demo", Type: model.EventDeployed})
+
+		store = reopen()
+		if _, err := store.GetContainer("web"); err != nil {
+			t.Errorf("container lost after reopening: %v", err)
+		}
+		if _, err := store.GetProject("demo"); err != nil {
+			t.Errorf("project lost after reopening: %v", err)
+		}
+		if _, err := store.GetRevision("demo", 1); err != nil {
+			t.Errorf("revision lost after reopening: %v", err)
+		}
+		if events, _ := store.GetEvents("demo"); len(events) != 1 {
+			t.Errorf("got %d events after reopening, want 1", len(events))
+		}
+		if err := store.AddEvent(model.Event{Project: "demo", Type: model.EventStopped}); err != nil {
+			t.Fatal(err)
+		}
+		if events, _ := store.GetEvents("demo"); len(events) != 2 || events[1].ID != 2 {
+			t.Errorf("event IDs not continued after reopening: %+v", events)
+		}
+	})
+}
+
+func revisionNumbers(revisions []model.Revision) []int {
+	numbers := make([]int, 0, len(revisions))
+	for _, revision := range revisions {
+		numbers = append(numbers, revision.Number)
+	}
+	return numbers
+}